@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/anonymize"
+	"github.com/slashdoom/aruba_exporter/transcript"
+)
+
+// anonymizeFile is a file to anonymize and its path relative to the argument it was found in
+type anonymizeFile struct {
+	path    string
+	rel     string
+	content string
+}
+
+// runAnonymize implements the anonymize subcommand. It replaces host names, addresses, serial numbers and
+// SSIDs in transcripts or samples with consistent pseudonyms and removes echos and prompts of transcripts.
+func runAnonymize(args []string, w io.Writer) int {
+	fs := flag.NewFlagSet("anonymize", flag.ContinueOnError)
+	fs.SetOutput(w)
+	mapping := fs.String("mapping", "", "File the pseudonyms are loaded from and saved to, so several runs use the same pseudonyms (contains the original values)")
+	output := fs.String("output", "", "Directory the anonymized files are written to, keeping their relative paths. A single file is written to stdout if not set")
+	fs.Usage = func() {
+		fmt.Fprintln(w, "Usage: aruba_exporter anonymize [-mapping <file>] [-output <directory>] <file or directory> ...\n\nParameters:")
+		fmt.Fprintln(w)
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(w, "anonymize requires at least one file or directory")
+		return 2
+	}
+
+	a := anonymize.New()
+	if len(*mapping) > 0 {
+		err = a.LoadMapping(*mapping)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintln(w, err)
+			return 2
+		}
+	}
+
+	files, err := readAnonymizeFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 2
+	}
+	if len(*output) == 0 && len(files) != 1 {
+		fmt.Fprintf(w, "found %d files, anonymizing more than one file requires -output\n", len(files))
+		return 2
+	}
+
+	// names are learned from all files first, so they are also replaced in files which do not name them
+	for _, f := range files {
+		a.Learn(f.content)
+	}
+
+	for _, f := range files {
+		content := a.Anonymize(transcript.Clean(filepath.Base(f.path), f.content))
+		if len(*output) == 0 {
+			fmt.Fprint(w, content)
+			continue
+		}
+
+		parts := strings.Split(filepath.ToSlash(f.rel), "/")
+		for i, p := range parts {
+			parts[i] = a.Anonymize(p)
+		}
+		path := filepath.Join(append([]string{*output}, parts...)...)
+
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			fmt.Fprintln(w, err)
+			return 1
+		}
+		fmt.Fprintf(w, "%s -> %s\n", f.path, path)
+	}
+
+	if len(*mapping) > 0 {
+		err = a.SaveMapping(*mapping)
+		if err != nil {
+			fmt.Fprintln(w, err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func readAnonymizeFiles(args []string) ([]anonymizeFile, error) {
+	files := []anonymizeFile{}
+	for _, arg := range args {
+		paths, err := anonymize.Files([]string{arg})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			rel, err := filepath.Rel(arg, path)
+			if err != nil || rel == "." {
+				rel = filepath.Base(path)
+			}
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, anonymizeFile{path: path, rel: rel, content: strings.ReplaceAll(string(b), "\r", "")})
+		}
+	}
+
+	return files, nil
+}
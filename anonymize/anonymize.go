@@ -0,0 +1,427 @@
+// Package anonymize replaces host names, IP addresses, MAC addresses, serial numbers and SSIDs in command
+// outputs with consistent pseudonyms, so outputs of real devices can be shared as samples.
+package anonymize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/slashdoom/aruba_exporter/util"
+
+	"github.com/pkg/errors"
+)
+
+// Mapping holds the pseudonyms by original value. It can be saved and loaded again,
+// so several runs use the same pseudonyms.
+type Mapping struct {
+	Hosts        map[string]string `json:"hosts"`
+	Serials      map[string]string `json:"serials"`
+	SSIDs        map[string]string `json:"ssids"`
+	MACs         map[string]string `json:"macs"`
+	IPv4Networks map[string]string `json:"ipv4_networks"`
+	IPv6         map[string]string `json:"ipv6"`
+}
+
+// Anonymizer replaces sensitive values with pseudonyms. Names, serial numbers and SSIDs are recognized by
+// the context they appear in (e.g. "Hostname : sw1"), so they should be learned from all outputs first.
+// They are then replaced wherever they appear, also in outputs without that context.
+type Anonymizer struct {
+	m Mapping
+}
+
+var (
+	hostRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`(?im)^\s*(?:hostname|host name|system name|sysname|ap name)\s*[:=]?\s*"?([A-Za-z0-9][\w.-]*)"?\s*$`),
+		regexp.MustCompile(`(?m)^name:([A-Za-z0-9][\w.-]*)\s*$`),
+		// prompts, e.g. "switch1# show version" or "(controller1) #"
+		regexp.MustCompile(`(?m)^\(?([A-Za-z0-9][\w.-]*)\)?\s?(?:\([^)]*\))?\s?#\s*(?:$|show\b|display\b|no\b|top\b)`),
+	}
+	serialRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`(?im)\bserial(?:\s*(?:number|nbr|num|no\.?|#))?\s*[:=]?\s+"?([A-Za-z0-9][A-Za-z0-9-]{3,})`),
+		regexp.MustCompile(`(?m)\b(?:S/N|SN)\s*[:=]\s*([A-Za-z0-9][A-Za-z0-9-]{3,})`),
+	}
+	ssidRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`(?im)\be?ssid\s*:\s*"?([^"\n]*[^"\s])"?\s*$`),
+		regexp.MustCompile(`(?im)^\s*(?:wlan\s+)?ssid-profile\s+"?([^"\n]*[^"\s])"?\s*$`),
+		regexp.MustCompile(`(?im)^\s*essid\s+(?:"([^"\n]+)"|(\S+))\s*$`),
+	}
+
+	macRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b[0-9a-f]{2}[:-][0-9a-f]{2}(?:[:-][0-9a-f]{2}){4}\b`),
+		regexp.MustCompile(`(?i)\b[0-9a-f]{4}\.[0-9a-f]{4}\.[0-9a-f]{4}\b`),
+		regexp.MustCompile(`(?i)\b[0-9a-f]{6}-[0-9a-f]{6}\b`),
+	}
+	ipv4Regexp    = regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`)
+	ipv6Regexp    = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`)
+	versionRegexp = regexp.MustCompile(`(?i)version\s*:?\s*$`)
+)
+
+// New creates an anonymizer without any pseudonyms
+func New() *Anonymizer {
+	return &Anonymizer{
+		m: Mapping{
+			Hosts:        make(map[string]string),
+			Serials:      make(map[string]string),
+			SSIDs:        make(map[string]string),
+			MACs:         make(map[string]string),
+			IPv4Networks: make(map[string]string),
+			IPv6:         make(map[string]string),
+		},
+	}
+}
+
+// LoadMapping adds the pseudonyms saved to a file by SaveMapping
+func (a *Anonymizer) LoadMapping(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var m Mapping
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return errors.Wrapf(err, "could not parse mapping %s", path)
+	}
+
+	for _, p := range []struct{ from, to map[string]string }{
+		{m.Hosts, a.m.Hosts}, {m.Serials, a.m.Serials}, {m.SSIDs, a.m.SSIDs},
+		{m.MACs, a.m.MACs}, {m.IPv4Networks, a.m.IPv4Networks}, {m.IPv6, a.m.IPv6},
+	} {
+		for k, v := range p.from {
+			p.to[k] = v
+		}
+	}
+
+	return nil
+}
+
+// SaveMapping writes the pseudonyms to a file. The file contains the original values, so it must not be shared.
+func (a *Anonymizer) SaveMapping(path string) error {
+	b, err := json.MarshalIndent(a.m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// Learn finds host names, serial numbers and SSIDs in an output
+func (a *Anonymizer) Learn(output string) {
+	learn := func(res []*regexp.Regexp, m map[string]string, pseudonym func(string, int) string) {
+		for _, re := range res {
+			for _, match := range re.FindAllStringSubmatch(output, -1) {
+				for _, v := range match[1:] {
+					if v != "" {
+						add(m, v, pseudonym)
+					}
+				}
+			}
+		}
+	}
+
+	learn(hostRegexps, a.m.Hosts, hostPseudonym)
+	learn(serialRegexps, a.m.Serials, serialPseudonym)
+	learn(ssidRegexps, a.m.SSIDs, func(_ string, n int) string { return "ssid" + strconv.Itoa(n) })
+}
+
+// Anonymize replaces all known and recognized sensitive values in an output
+func (a *Anonymizer) Anonymize(output string) string {
+	output = replace(output, a.namesRegexp(), isNameBoundary, func(s string) (string, bool) {
+		for _, m := range []map[string]string{a.m.Hosts, a.m.Serials, a.m.SSIDs} {
+			if v, found := m[s]; found {
+				return v, true
+			}
+		}
+		return "", false
+	})
+
+	for _, re := range macRegexps {
+		output = replace(output, re, isBoundary, a.mac)
+	}
+	output = replace(output, ipv6Regexp, isBoundary, a.ipv6)
+	output = replaceIPv4(output, a.ipv4)
+
+	return output
+}
+
+// namesRegexp matches all learned names, longer names first, so a name is not replaced within a longer one
+func (a *Anonymizer) namesRegexp() *regexp.Regexp {
+	names := []string{}
+	for _, m := range []map[string]string{a.m.Hosts, a.m.Serials, a.m.SSIDs} {
+		for k := range m {
+			names = append(names, regexp.QuoteMeta(k))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	return regexp.MustCompile(strings.Join(names, "|"))
+}
+
+func (a *Anonymizer) mac(s string) (string, bool) {
+	// group addresses (e.g. multicast and broadcast) and unset addresses do not identify a device
+	key := util.StandardizeMacAddr(s)
+	if len(key) != 17 || key == "00-00-00-00-00-00" || strings.ContainsAny(key[1:2], "13579BDF") {
+		return "", false
+	}
+
+	pseudonym := nextPseudonym(a.m.MACs, key, func(k string, n int) string {
+		return fmt.Sprintf("%s-%02X-%02X-%02X", k[:8], (n>>16)&0xff, (n>>8)&0xff, n&0xff)
+	})
+
+	// the pseudonym is written in the format of the original (separators and case)
+	digits := strings.ReplaceAll(pseudonym, "-", "")
+	var b strings.Builder
+	i := 0
+	for _, c := range s {
+		if !isHex(c) {
+			b.WriteRune(c)
+			continue
+		}
+		d := rune(digits[i])
+		if c >= 'a' && c <= 'f' || (c >= '0' && c <= '9' && strings.ToLower(s) == s) {
+			d = []rune(strings.ToLower(string(d)))[0]
+		}
+		b.WriteRune(d)
+		i++
+	}
+
+	return b.String(), true
+}
+
+func (a *Anonymizer) ipv4(octets []int) (string, bool) {
+	switch {
+	case octets[0] == 0, octets[0] == 127, octets[0] >= 224:
+		return "", false
+	}
+
+	network := fmt.Sprintf("%d.%d.%d", octets[0], octets[1], octets[2])
+	pseudonym := nextPseudonym(a.m.IPv4Networks, network, func(_ string, n int) string {
+		return fmt.Sprintf("10.%d.%d", (n>>8)&0xff, n&0xff)
+	})
+
+	return fmt.Sprintf("%s.%d", pseudonym, octets[3]), true
+}
+
+func (a *Anonymizer) ipv6(s string) (string, bool) {
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() != nil || ip.IsUnspecified() || ip.IsLoopback() || (strings.Count(s, ":") == 5 && !strings.Contains(s, "::")) {
+		return "", false
+	}
+
+	key := ip.String()
+	return nextPseudonym(a.m.IPv6, key, func(_ string, n int) string {
+		return fmt.Sprintf("2001:db8::%x", n)
+	}), true
+}
+
+// replace replaces the matches of a regular expression which are not part of a longer value.
+// The spaces after a replacement are adjusted, so the columns of tables stay aligned.
+func replace(s string, re *regexp.Regexp, boundary func(string, int, int) bool, pseudonym func(string) (string, bool)) string {
+	if re == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		if !boundary(s, start, end) {
+			continue
+		}
+		p, ok := pseudonym(s[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(s[last:start])
+		last = writeAligned(&b, s, end, p, end-start)
+	}
+	b.WriteString(s[last:])
+
+	return b.String()
+}
+
+func replaceIPv4(s string, pseudonym func([]int) (string, bool)) string {
+	return replace(s, ipv4Regexp, isBoundary, func(match string) (string, bool) {
+		parts := strings.Split(match, ".")
+		octets := make([]int, 4)
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n > 255 {
+				return "", false
+			}
+			octets[i] = n
+		}
+
+		return pseudonym(octets)
+	})
+}
+
+// isBoundary checks that a match is not part of a longer word, version or address (e.g. Version 8.7.1.6)
+func isBoundary(s string, start, end int) bool {
+	if start > 0 {
+		switch c := s[start-1]; {
+		case c == ':' || c == '.' || c == '-' || c == '_' || isHex(rune(c)):
+			return false
+		}
+		if versionRegexp.MatchString(s[:start]) {
+			return false
+		}
+	}
+	if end < len(s) {
+		switch c := s[end]; {
+		case c == ':' || c == '_' || isHex(rune(c)):
+			return false
+		case (c == '-' || c == '.') && end+1 < len(s) && s[end+1] >= '0' && s[end+1] <= '9':
+			return false
+		}
+	}
+
+	return true
+}
+
+// isNameBoundary checks that a match is not part of a longer name (e.g. sw1 in sw1-lag)
+func isNameBoundary(s string, start, end int) bool {
+	if start > 0 {
+		if c := rune(s[start-1]); c == '.' || c == '-' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return false
+		}
+	}
+	if end < len(s) {
+		if c := rune(s[end]); c == '-' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeAligned writes a replacement and the spaces following the original value,
+// adjusted by the difference in length as long as at least one space is kept
+func writeAligned(b *strings.Builder, s string, end int, replacement string, length int) int {
+	b.WriteString(replacement)
+
+	spaces := 0
+	for end+spaces < len(s) && s[end+spaces] == ' ' {
+		spaces++
+	}
+	if spaces == 0 {
+		return end
+	}
+
+	n := spaces + length - len(replacement)
+	if n < 1 {
+		n = 1
+	}
+	b.WriteString(strings.Repeat(" ", n))
+
+	return end + spaces
+}
+
+// add adds a pseudonym for a value if it has none yet
+func add(m map[string]string, value string, pseudonym func(string, int) string) {
+	nextPseudonym(m, value, pseudonym)
+}
+
+// nextPseudonym gets the pseudonym of a value, a new one is created with the next unused number
+func nextPseudonym(m map[string]string, value string, pseudonym func(string, int) string) string {
+	if p, found := m[value]; found {
+		return p
+	}
+
+	used := make(map[string]bool, len(m))
+	for _, p := range m {
+		used[p] = true
+	}
+
+	n := len(m) + 1
+	for used[pseudonym(value, n)] {
+		n++
+	}
+
+	p := pseudonym(value, n)
+	m[value] = p
+	return p
+}
+
+func hostPseudonym(value string, n int) string {
+	if strings.Contains(value, ".") {
+		return fmt.Sprintf("host%d.example.com", n)
+	}
+
+	return fmt.Sprintf("host%d", n)
+}
+
+// serialPseudonym keeps the length of the serial number, e.g. SN00000001 for a serial number with 10 characters
+func serialPseudonym(value string, n int) string {
+	s := strconv.Itoa(n)
+	if pad := len(value) - len(s) - 2; pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+
+	return "SN" + s
+}
+
+func isHex(c rune) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// Files gets the files to anonymize in the given files and directories. Rotated transcripts (e.g. show version.1) are skipped.
+func Files(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = walk(path, &files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+var rotatedRegexp = regexp.MustCompile(`\.\d+$`)
+
+func walk(dir string, files *[]string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		path := dir + string(os.PathSeparator) + info.Name()
+		switch {
+		case info.IsDir():
+			err = walk(path, files)
+			if err != nil {
+				return err
+			}
+		case !rotatedRegexp.MatchString(info.Name()):
+			*files = append(*files, path)
+		}
+	}
+
+	return nil
+}
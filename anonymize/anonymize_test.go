@@ -0,0 +1,70 @@
+package anonymize_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/anonymize"
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func anonymized(a *anonymize.Anonymizer, t *testing.T) string {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "input.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Learn(string(b))
+	return a.Anonymize(string(b))
+}
+
+func TestAnonymize(t *testing.T) {
+	golden.AssertText(t, "anonymized", anonymized(anonymize.New(), t))
+}
+
+func TestAnonymizeConsistent(t *testing.T) {
+	got := anonymize.New().Anonymize("90:20:c2:1a:2b:3c 9020.c21a.2b3c 90-20-C2-1A-2B-3C 9020c2-1a2b3c 9020c2-1a2b3d")
+
+	want := "90:20:c2:00:00:01 9020.c200.0001 90-20-C2-00-00-01 9020c2-000001 9020c2-000002"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAnonymizeKeepsColumns(t *testing.T) {
+	a := anonymize.New()
+	a.Learn("Hostname : a-very-long-host-name\n")
+
+	got := a.Anonymize("a-very-long-host-name  1/1/1  up\nshort                  1/1/2  up\n")
+	lines := strings.Split(got, "\n")
+	if strings.Index(lines[0], "1/1/1") != strings.Index(lines[1], "1/1/2") {
+		t.Errorf("columns are not aligned:\n%s", got)
+	}
+}
+
+func TestMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.json")
+
+	a := anonymize.New()
+	want := anonymized(a, t)
+	err := a.SaveMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pseudonyms are taken from the mapping, even if the values are learned in a different order
+	b := anonymize.New()
+	err = b.LoadMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Learn("Hostname : other\nSystem Name : access-sw2\n")
+
+	if got := anonymized(b, t); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
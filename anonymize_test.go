@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+func TestAnonymize(t *testing.T) {
+	dir := t.TempDir()
+	mapping := filepath.Join(dir, "mapping.json")
+	output := filepath.Join(dir, "samples")
+
+	var b bytes.Buffer
+	code := runAnonymize([]string{"-mapping", mapping, "-output", output, "samples/system/ArubaCXSwitch"}, &b)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, b.String())
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(output, "show system"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"TESTsw01", "XX00XXX00X", "7890ab"} {
+		if strings.Contains(string(content), s) {
+			t.Errorf("%q was not anonymized:\n%s", s, content)
+		}
+	}
+
+	// the anonymized outputs can still be parsed
+	b.Reset()
+	code = runParse([]string{"-collector", "system", "-os", rpc.ArubaCXSwitch, "-file", output}, &b)
+	if code != 0 || !strings.Contains(b.String(), "aruba_system_version") {
+		t.Errorf("exit code %d: %s", code, b.String())
+	}
+
+	// the mapping is reused, so a single file gets the same pseudonyms
+	b.Reset()
+	code = runAnonymize([]string{"-mapping", mapping, "samples/system/ArubaCXSwitch/show system"}, &b)
+	if code != 0 || b.String() != string(content) {
+		t.Errorf("exit code %d, got:\n%s\nwant:\n%s", code, b.String(), content)
+	}
+}
+
+func TestAnonymizeInvalidArguments(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"samples/system/ArubaCXSwitch"},
+		{"samples/missing"},
+	}
+
+	for _, args := range tests {
+		var b bytes.Buffer
+		if code := runAnonymize(args, &b); code != 2 {
+			t.Errorf("%v: got exit code %d, want 2", args, code)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 	"sync"
 
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/config"
 	"github.com/slashdoom/aruba_exporter/connector"
 	"github.com/slashdoom/aruba_exporter/rpc"
 	
@@ -21,31 +23,44 @@ var (
 )
 
 func init() {
-	upDesc = prometheus.NewDesc(prefix+"up", "Scrape of target was successful", []string{"target"}, nil)
-	scrapeDurationDesc = prometheus.NewDesc(prefix+"collector_duration_seconds", "Duration of a collector scrape for one target", []string{"target"}, nil)
-	scrapeCollectorDurationDesc = prometheus.NewDesc(prefix+"collect_duration_seconds", "Duration of a scrape by collector and target", []string{"target", "collector"}, nil)
+	upDesc = collector.NewDesc(prefix+"up", "Scrape of target was successful", []string{"target"})
+	scrapeDurationDesc = collector.NewDesc(prefix+"collector_duration_seconds", "Duration of a collector scrape for one target", []string{"target"})
+	scrapeCollectorDurationDesc = collector.NewDesc(prefix+"collect_duration_seconds", "Duration of a scrape by collector and target", []string{"target", "collector"})
 }
 
 type arubaCollector struct {
 	devices    []*connector.Device
 	collectors *collectors
+	labels     *customLabels
+	cfg        *config.Config
 }
 
-func newArubaCollector(devices []*connector.Device) *arubaCollector {
+func newArubaCollector(devices []*connector.Device, cfg *config.Config) *arubaCollector {
 	return &arubaCollector{
 		devices:    devices,
 		collectors: collectorsForDevices(devices, cfg),
+		labels:     customLabelsForDevices(devices, cfg),
+		cfg:        cfg,
 	}
 }
 
 // Describe implements prometheus.Collector interface
 func (c *arubaCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- upDesc
-	ch <- scrapeDurationDesc
-	ch <- scrapeCollectorDurationDesc
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		descs <- upDesc
+		descs <- scrapeDurationDesc
+		descs <- scrapeCollectorDurationDesc
+		descs <- collector.UnsupportedDesc
+
+		for _, col := range c.collectors.allEnabledCollectors() {
+			col.Describe(descs)
+		}
+		close(descs)
+	}()
 
-	for _, col := range c.collectors.allEnabledCollectors() {
-		col.Describe(ch)
+	for d := range descs {
+		ch <- c.labels.desc(d)
 	}
 }
 
@@ -61,17 +76,31 @@ func (c *arubaCollector) Collect(ch chan<- prometheus.Metric) {
 	wg.Wait()
 }
 
-func (c *arubaCollector) collectForHost(device *connector.Device, ch chan<- prometheus.Metric, wg *sync.WaitGroup) {
+func (c *arubaCollector) collectForHost(device *connector.Device, out chan<- prometheus.Metric, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	l := []string{device.Host}
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		values := c.labels.values(device)
+		for m := range ch {
+			out <- c.labels.metric(m, values)
+		}
+		close(done)
+	}()
+	defer func() {
+		close(ch)
+		<-done
+	}()
+
+	l := []string{device.DeviceConfig.Host}
 
 	t := time.Now()
 	defer func() {
 		ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(t).Seconds(), l...)
 	}()
 
-	conn, err := connector.NewSSSHConnection(device, cfg)
+	conn, err := connector.NewSSSHConnection(device, c.cfg)
 	if err != nil {
 		log.Errorln(err)
 		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 0, l...)
@@ -81,17 +110,24 @@ func (c *arubaCollector) collectForHost(device *connector.Device, ch chan<- prom
 
 	ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 1, l...)
 
-	client := rpc.NewClient(conn, cfg.Level)
-	err = client.Identify()
-	if err != nil {
-		log.Errorln(device.Host + ": " + err.Error())
-		return
+	client := rpc.NewClient(conn, c.cfg.Level)
+	if device.DeviceConfig.OSType != "" {
+		client.OSType = device.DeviceConfig.OSType
+	} else {
+		conn.Transcript.SetCollector("identify")
+		err = client.Identify()
+		if err != nil {
+			log.Errorln(device.String() + ": " + err.Error())
+			return
+		}
 	}
+	conn.Transcript.SetOSType(client.OSType)
 
 	log.Debugf("collectors: %+v", c.collectors.collectorsForDevice(device))
 	for _, col := range c.collectors.collectorsForDevice(device) {
 		ct := time.Now()
 		log.Debugf("collector: %v", col)
+		conn.Transcript.SetCollector(c.collectors.name(col))
 		err := col.Collect(client, ch, l)
 
 		if err != nil && err.Error() != "EOF" {
@@ -0,0 +1,17 @@
+package bgp
+
+// Neighbor is a BGP session with a neighbor for an address family in a VRF.
+// Prefix counts are -1 if the output does not contain them.
+type Neighbor struct {
+	VRF                string
+	AddressFamily      string
+	Neighbor           string
+	RemoteAS           string
+	State              string
+	Uptime             float64
+	PrefixesReceived   float64
+	PrefixesAccepted   float64
+	PrefixesAdvertised float64
+	MessagesReceived   float64
+	MessagesSent       float64
+}
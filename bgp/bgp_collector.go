@@ -0,0 +1,154 @@
+package bgp
+
+import (
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_bgp_"
+
+var (
+	SessionUpDesc          *prometheus.Desc
+	SessionStateDesc       *prometheus.Desc
+	SessionUptimeDesc      *prometheus.Desc
+	PrefixesReceivedDesc   *prometheus.Desc
+	PrefixesAcceptedDesc   *prometheus.Desc
+	PrefixesAdvertisedDesc *prometheus.Desc
+	MessagesReceivedDesc   *prometheus.Desc
+	MessagesSentDesc       *prometheus.Desc
+
+	// states are the values of the session state metric, as bgpPeerState of the BGP4-MIB
+	states = map[string]float64{
+		"idle":        1,
+		"connect":     2,
+		"active":      3,
+		"opensent":    4,
+		"openconfirm": 5,
+		"established": 6,
+	}
+)
+
+func init() {
+	collector.Register("bgp", false, NewCollector)
+
+	l := []string{"target", "vrf", "neighbor", "address_family", "remote_as"}
+
+	SessionUpDesc = collector.NewDesc(prefix+"session_up", "Session is established", l)
+	SessionStateDesc = collector.NewDesc(prefix+"session_state", "State of the session: 1 = idle, 2 = connect, 3 = active, 4 = opensent, 5 = openconfirm, 6 = established, 0 = unknown", l)
+	SessionUptimeDesc = collector.NewDesc(prefix+"session_uptime_seconds", "Time since the last state change of the session in seconds", l)
+	PrefixesReceivedDesc = collector.NewDesc(prefix+"prefixes_received", "Number of prefixes received from the neighbor", l)
+	PrefixesAcceptedDesc = collector.NewDesc(prefix+"prefixes_accepted", "Number of prefixes received from the neighbor and accepted", l)
+	PrefixesAdvertisedDesc = collector.NewDesc(prefix+"prefixes_advertised", "Number of prefixes advertised to the neighbor", l)
+	MessagesReceivedDesc = collector.NewDesc(prefix+"messages_received_total", "Number of messages received from the neighbor", l)
+	MessagesSentDesc = collector.NewDesc(prefix+"messages_sent_total", "Number of messages sent to the neighbor", l)
+}
+
+type bgpCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &bgpCollector{
+		parsers: collector.NewParsers("bgp"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *bgpCollector) registerParsers() {
+	// depending on the firmware only one of the commands for all VRFs is available, the parser
+	// skips the error output of the other. The prefix counts are only in the neighbor details.
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType: rpc.ArubaCXSwitch,
+		Commands: []string{
+			"show bgp all summary", "show bgp vrf all all-vrf summary",
+			"show bgp all neighbors", "show bgp vrf all all-vrf neighbors",
+		},
+		Parse: func(out string) (interface{}, error) { return c.ParseArubaCXNeighbors(out) },
+	})
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaController,
+		Commands: []string{"show ip bgp summary"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaControllerNeighbors(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *bgpCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*bgpCollector) Name() string {
+	return "BGP"
+}
+
+func (c *bgpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- SessionUpDesc
+	ch <- SessionStateDesc
+	ch <- SessionUptimeDesc
+	ch <- PrefixesReceivedDesc
+	ch <- PrefixesAcceptedDesc
+	ch <- PrefixesAdvertisedDesc
+	ch <- MessagesReceivedDesc
+	ch <- MessagesSentDesc
+}
+
+func (c *bgpCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("neighbors", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "neighbors", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range parsed.(map[string]*Neighbor) {
+		l := append(labelValues, n.VRF, n.Neighbor, n.AddressFamily, n.RemoteAS)
+
+		state := stateValue(n.State)
+		up := 0
+		if state == states["established"] {
+			up = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(SessionUpDesc, prometheus.GaugeValue, float64(up), l...)
+		ch <- prometheus.MustNewConstMetric(SessionStateDesc, prometheus.GaugeValue, state, l...)
+		if n.Uptime >= 0 {
+			ch <- prometheus.MustNewConstMetric(SessionUptimeDesc, prometheus.GaugeValue, n.Uptime, l...)
+		}
+		if n.PrefixesReceived >= 0 {
+			ch <- prometheus.MustNewConstMetric(PrefixesReceivedDesc, prometheus.GaugeValue, n.PrefixesReceived, l...)
+		}
+		if n.PrefixesAccepted >= 0 {
+			ch <- prometheus.MustNewConstMetric(PrefixesAcceptedDesc, prometheus.GaugeValue, n.PrefixesAccepted, l...)
+		}
+		if n.PrefixesAdvertised >= 0 {
+			ch <- prometheus.MustNewConstMetric(PrefixesAdvertisedDesc, prometheus.GaugeValue, n.PrefixesAdvertised, l...)
+		}
+		if n.MessagesReceived >= 0 {
+			ch <- prometheus.MustNewConstMetric(MessagesReceivedDesc, prometheus.CounterValue, n.MessagesReceived, l...)
+		}
+		if n.MessagesSent >= 0 {
+			ch <- prometheus.MustNewConstMetric(MessagesSentDesc, prometheus.CounterValue, n.MessagesSent, l...)
+		}
+	}
+
+	return nil
+}
+
+// stateValue gets the value of the session state metric, details like "(Admin)" in "Idle (Admin)" are ignored
+func stateValue(state string) float64 {
+	state = strings.ToLower(state)
+	if i := strings.IndexAny(state, " ("); i >= 0 {
+		state = state[:i]
+	}
+
+	return states[state]
+}
@@ -0,0 +1,19 @@
+package bgp
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseNeighbors(f *testing.F) {
+	golden.Seeds(f, "bgp")
+	c := NewCollector().(*bgpCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("neighbors") {
+			neighbors, _ := p.Parse(out)
+			golden.CheckValues(t, neighbors)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package bgp
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultVRF = "default"
+
+var (
+	vrfRegexp           = regexp.MustCompile(`^\s*VRF\s*:\s*(\S+)`)
+	addressFamilyRegexp = regexp.MustCompile(`^\s*Address-family\s*:\s*(.*?)\s*$`)
+	uptimeUnitRegexp    = regexp.MustCompile(`(\d+)([wdhms])`)
+	uptimeClockRegexp   = regexp.MustCompile(`^(\d+):(\d+):(\d+)$`)
+	detailRegexp        = regexp.MustCompile(`^\s*BGP Neighbor (\S+)`)
+	prefixesRegexp      = regexp.MustCompile(`Prefixes (Received|Accepted|Advertised)\s*:\s*(\d+)`)
+
+	// a command echo or the error of a command not available in the firmware ends the output of a command
+	commandRegexp      = regexp.MustCompile(`^\S+#\s*\S`)
+	invalidInputRegexp = regexp.MustCompile(`^\s*(?:%\s*)?Invalid input`)
+
+	// columns maps the headers of the summary tables to the fields of a neighbor
+	columns = map[string]string{
+		"Neighbor":     "neighbor",
+		"Remote-AS":    "as",
+		"AS":           "as",
+		"MsgRcvd":      "received",
+		"MsgSent":      "sent",
+		"Up/Down":      "uptime",
+		"Up/Down Time": "uptime",
+		"State":        "state",
+		"State/PfxRcd": "state_prefixes",
+		"PfxRcd":       "prefixes_received",
+		"PfxAcc":       "prefixes_accepted",
+		"PfxSnt":       "prefixes_advertised",
+		"PfxAdv":       "prefixes_advertised",
+	}
+)
+
+// ParseArubaCXNeighbors parses the summaries of "show bgp all summary" and "show bgp vrf all all-vrf summary".
+// Each VRF starts with "VRF : <name>" and has a table per address family, output without a VRF is the default VRF.
+// The outputs of both commands can be joined, neighbors found in both are the same. The summaries have no
+// prefix counts, they are taken from the "BGP Neighbor <address>" blocks of the neighbor details, which have
+// a "Prefixes Received/Accepted/Advertised" section per address family.
+func (c *bgpCollector) ParseArubaCXNeighbors(output string) (map[string]*Neighbor, error) {
+	neighbors := make(map[string]*Neighbor)
+	details := make(map[string]*Neighbor)
+
+	vrf := defaultVRF
+	family := ""
+	var header []string
+	var detail *Neighbor
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if commandRegexp.MatchString(line) || invalidInputRegexp.MatchString(line) {
+			log.Tracef("end of command output: %s", line)
+			vrf = defaultVRF
+			family = ""
+			header = nil
+			detail = nil
+			continue
+		}
+		if m := vrfRegexp.FindStringSubmatch(line); m != nil {
+			vrf = m[1]
+			family = ""
+			header = nil
+			detail = nil
+			continue
+		}
+		if m := detailRegexp.FindStringSubmatch(line); m != nil {
+			detail = &Neighbor{VRF: vrf, Neighbor: m[1]}
+			family = ""
+			header = nil
+			continue
+		}
+		if m := addressFamilyRegexp.FindStringSubmatch(line); m != nil {
+			family = m[1]
+			header = nil
+			continue
+		}
+		if h := parseHeader(line); h != nil {
+			header = h
+			detail = nil
+			continue
+		}
+		if detail != nil {
+			parsePrefixes(details, detail, family, line)
+			continue
+		}
+		if header == nil {
+			continue
+		}
+
+		n := parseNeighbor(header, line)
+		if n == nil {
+			log.Tracef("skipping line: %s", line)
+			continue
+		}
+		n.VRF = vrf
+		n.AddressFamily = family
+		neighbors[key(n)] = n
+	}
+
+	for k, d := range details {
+		n, found := neighbors[k]
+		if !found {
+			log.Tracef("no summary for neighbor %s", k)
+			continue
+		}
+		n.PrefixesReceived = d.PrefixesReceived
+		n.PrefixesAccepted = d.PrefixesAccepted
+		n.PrefixesAdvertised = d.PrefixesAdvertised
+	}
+
+	return neighbors, nil
+}
+
+// parsePrefixes adds the prefix counts of a line of the neighbor details to the address family of the neighbor
+func parsePrefixes(details map[string]*Neighbor, detail *Neighbor, family, line string) {
+	matches := prefixesRegexp.FindAllStringSubmatch(line, -1)
+	if matches == nil || family == "" {
+		return
+	}
+
+	k := key(&Neighbor{VRF: detail.VRF, AddressFamily: family, Neighbor: detail.Neighbor})
+	d, found := details[k]
+	if !found {
+		d = &Neighbor{PrefixesReceived: -1, PrefixesAccepted: -1, PrefixesAdvertised: -1}
+		details[k] = d
+	}
+	for _, m := range matches {
+		switch m[1] {
+		case "Received":
+			d.PrefixesReceived = util.Str2float64(m[2])
+		case "Accepted":
+			d.PrefixesAccepted = util.Str2float64(m[2])
+		case "Advertised":
+			d.PrefixesAdvertised = util.Str2float64(m[2])
+		}
+	}
+}
+
+// ParseArubaControllerNeighbors parses "show ip bgp summary" of controllers and gateways, which only have the
+// default VRF and IPv4 unicast. The last column is the state, or the number of received prefixes if established.
+func (c *bgpCollector) ParseArubaControllerNeighbors(output string) (map[string]*Neighbor, error) {
+	neighbors := make(map[string]*Neighbor)
+
+	var header []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if h := parseHeader(line); h != nil {
+			header = h
+			continue
+		}
+		if header == nil {
+			continue
+		}
+
+		n := parseNeighbor(header, line)
+		if n == nil {
+			log.Tracef("skipping line: %s", line)
+			continue
+		}
+		n.VRF = defaultVRF
+		n.AddressFamily = "IPv4 Unicast"
+		neighbors[key(n)] = n
+	}
+
+	return neighbors, nil
+}
+
+func key(n *Neighbor) string {
+	return n.VRF + "/" + n.AddressFamily + "/" + n.Neighbor
+}
+
+// parseHeader gets the fields of the columns of a summary table header, or nil if the line is no header
+func parseHeader(line string) []string {
+	fields := strings.Fields(strings.Replace(line, "Up/Down Time", "Up/Down_Time", 1))
+	if len(fields) < 3 || fields[0] != "Neighbor" {
+		return nil
+	}
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = columns[strings.Replace(f, "_", " ", 1)]
+	}
+
+	return header
+}
+
+// parseNeighbor parses a row of a summary table. If a row has more values than columns
+// (e.g. a state of "Idle (Admin)"), the remaining values belong to the last column.
+func parseNeighbor(header []string, line string) *Neighbor {
+	fields := strings.Fields(line)
+	if len(fields) < len(header) || net.ParseIP(fields[0]) == nil {
+		return nil
+	}
+	if len(fields) > len(header) {
+		fields = append(fields[:len(header)-1], strings.Join(fields[len(header)-1:], " "))
+	}
+
+	n := &Neighbor{
+		PrefixesReceived:   -1,
+		PrefixesAccepted:   -1,
+		PrefixesAdvertised: -1,
+	}
+	for i, column := range header {
+		v := fields[i]
+		switch column {
+		case "neighbor":
+			n.Neighbor = v
+		case "as":
+			n.RemoteAS = v
+		case "received":
+			n.MessagesReceived = util.Str2float64(v)
+		case "sent":
+			n.MessagesSent = util.Str2float64(v)
+		case "uptime":
+			n.Uptime = parseUptime(v)
+		case "state":
+			n.State = v
+		case "state_prefixes":
+			if p := util.Str2float64(v); p >= 0 {
+				n.State = "Established"
+				n.PrefixesReceived = p
+			} else {
+				n.State = v
+			}
+		case "prefixes_received":
+			n.PrefixesReceived = util.Str2float64(v)
+		case "prefixes_accepted":
+			n.PrefixesAccepted = util.Str2float64(v)
+		case "prefixes_advertised":
+			n.PrefixesAdvertised = util.Str2float64(v)
+		}
+	}
+
+	return n
+}
+
+// parseUptime converts the time since the last state change to seconds, e.g. 32d:04h:12m, 2d03h04m or 00:05:12
+func parseUptime(s string) float64 {
+	if s == "never" {
+		return 0
+	}
+
+	if m := uptimeClockRegexp.FindStringSubmatch(s); m != nil {
+		return util.Uptime2seconds("0", "0", m[1], m[2], m[3])
+	}
+
+	matches := uptimeUnitRegexp.FindAllStringSubmatch(s, -1)
+	if matches == nil {
+		return -1
+	}
+
+	units := map[string]float64{}
+	for _, m := range matches {
+		units[m[2]] = util.Str2float64(m[1])
+	}
+
+	return units["w"]*604800 + units["d"]*86400 + units["h"]*3600 + units["m"]*60 + units["s"]
+}
@@ -0,0 +1,163 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*bgpCollector)
+	golden.TestParsers(t, c.parsers, "bgp")
+}
+
+const cxSummary = `VRF : tenant-a
+ Address-family : IPv4 Unicast
+ -----------------------------
+ Neighbor      Remote-AS MsgRcvd MsgSent   Up/Down Time State        AdminStatus
+ 10.1.0.2      65200         811     806   02d:11h:40m  Established  Up
+`
+
+func TestParseArubaCXNeighbors(t *testing.T) {
+	c := &bgpCollector{}
+
+	tests := []struct {
+		name     string
+		output   string
+		vrf      string
+		prefixes []float64
+	}{
+		{
+			name:     "summary only",
+			output:   cxSummary,
+			vrf:      "tenant-a",
+			prefixes: []float64{-1, -1, -1},
+		},
+		{
+			name:     "invalid input of the other summary command",
+			output:   cxSummary + "Invalid input: vrf\n",
+			vrf:      "tenant-a",
+			prefixes: []float64{-1, -1, -1},
+		},
+		{
+			name: "details",
+			output: cxSummary + "sw1# show bgp vrf all all-vrf neighbors\nVRF : tenant-a\n BGP Neighbor 10.1.0.2 (External)\n" +
+				" Remote AS         : 65200\n Address-family : IPv4 Unicast\n" +
+				" Prefixes Received   : 310            Prefixes Accepted : 305\n Prefixes Advertised : 12\n",
+			vrf:      "tenant-a",
+			prefixes: []float64{310, 305, 12},
+		},
+		{
+			name: "details without VRF are in the default VRF",
+			output: cxSummary + "sw1# show bgp all neighbors\n BGP Neighbor 10.1.0.2 (External)\n" +
+				" Address-family : IPv4 Unicast\n Prefixes Received   : 1\n",
+			vrf:      "tenant-a",
+			prefixes: []float64{-1, -1, -1},
+		},
+		{
+			name: "details of another address family",
+			output: cxSummary + "VRF : tenant-a\n BGP Neighbor 10.1.0.2 (External)\n" +
+				" Address-family : IPv6 Unicast\n Prefixes Received   : 1\n",
+			vrf:      "tenant-a",
+			prefixes: []float64{-1, -1, -1},
+		},
+		{
+			name: "rows after a command echo are in the default VRF",
+			output: cxSummary + "sw1# show bgp all summary\n Address-family : IPv4 Unicast\n" +
+				" Neighbor      Remote-AS MsgRcvd MsgSent   Up/Down Time State        AdminStatus\n" +
+				" 10.1.0.2      65200         811     806   02d:11h:40m  Established  Up\n",
+			vrf:      "default",
+			prefixes: []float64{-1, -1, -1},
+		},
+	}
+
+	for _, test := range tests {
+		neighbors, err := c.ParseArubaCXNeighbors(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+
+		n, found := neighbors[test.vrf+"/IPv4 Unicast/10.1.0.2"]
+		if !found {
+			t.Errorf("%s: neighbor not found in %v", test.name, neighbors)
+			continue
+		}
+		got := []float64{n.PrefixesReceived, n.PrefixesAccepted, n.PrefixesAdvertised}
+		if !reflect.DeepEqual(got, test.prefixes) {
+			t.Errorf("%s: got prefixes %v, want %v", test.name, got, test.prefixes)
+		}
+	}
+}
+
+func TestParseMalformedNeighbors(t *testing.T) {
+	c := &bgpCollector{}
+	cxHeader := "VRF : default\n Address-family : IPv4 Unicast\n Neighbor      Remote-AS MsgRcvd MsgSent   Up/Down Time State        AdminStatus\n"
+	controllerHeader := "Neighbor        V         AS MsgRcvd MsgSent   TblVer  InQ OutQ Up/Down  State/PfxRcd\n"
+
+	tests := []struct {
+		name   string
+		parse  func(string) (map[string]*Neighbor, error)
+		output string
+		want   map[string][]float64
+	}{
+		{name: "empty output", parse: c.ParseArubaCXNeighbors, want: map[string][]float64{}},
+		{name: "empty table", parse: c.ParseArubaCXNeighbors, output: cxHeader, want: map[string][]float64{}},
+		{
+			name:   "rows without a header",
+			parse:  c.ParseArubaCXNeighbors,
+			output: " 10.1.0.2      65200         811     806   02d:11h:40m  Established  Up\n",
+			want:   map[string][]float64{},
+		},
+		{
+			name:   "missing column",
+			parse:  c.ParseArubaCXNeighbors,
+			output: cxHeader + " 10.1.0.2      65200         811     806   02d:11h:40m  Established\n",
+			want:   map[string][]float64{},
+		},
+		{
+			name:   "non-numeric counters and uptime",
+			parse:  c.ParseArubaCXNeighbors,
+			output: cxHeader + " 10.1.0.2      65200         n/a     806   unknown  Established  Up\n",
+			want:   map[string][]float64{"default/IPv4 Unicast/10.1.0.2": {-1, 806, -1}},
+		},
+		{name: "controller empty output", parse: c.ParseArubaControllerNeighbors, want: map[string][]float64{}},
+		{name: "controller empty table", parse: c.ParseArubaControllerNeighbors, output: controllerHeader + "\nTotal number of neighbors 0\n", want: map[string][]float64{}},
+		{
+			name:   "controller missing column",
+			parse:  c.ParseArubaControllerNeighbors,
+			output: controllerHeader + "10.10.10.2      4      65002   12345   12350        0    0    0 2d03h04m\n",
+			want:   map[string][]float64{},
+		},
+		{
+			name:   "controller row without an address",
+			parse:  c.ParseArubaControllerNeighbors,
+			output: controllerHeader + "peer1           4      65002   12345   12350        0    0    0 2d03h04m        8\n",
+			want:   map[string][]float64{},
+		},
+		{
+			name:   "controller non-numeric counters",
+			parse:  c.ParseArubaControllerNeighbors,
+			output: controllerHeader + "10.10.10.2      4      65002   -       12350        0    0    0 2d03h04m        8\n",
+			want:   map[string][]float64{"default/IPv4 Unicast/10.10.10.2": {-1, 12350, 183840}},
+		},
+	}
+
+	for _, test := range tests {
+		neighbors, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, neighbors)
+
+		got := make(map[string][]float64)
+		for k, n := range neighbors {
+			got[k] = []float64{n.MessagesReceived, n.MessagesSent, n.Uptime}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got received, sent and uptime %v, want %v", test.name, got, test.want)
+		}
+	}
+}
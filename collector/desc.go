@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	descsMu sync.RWMutex
+	descs   = make(map[*prometheus.Desc]DescInfo)
+	byName  = make(map[string]*prometheus.Desc)
+)
+
+// DescInfo holds the parameters a metric descriptor was created with
+type DescInfo struct {
+	FqName         string
+	Help           string
+	VariableLabels []string
+}
+
+// NewDesc creates a metric descriptor and remembers its parameters,
+// so it can be recreated with the custom labels configured for the devices.
+// Descriptors created again with the same parameters (e.g. after a reload) are reused.
+func NewDesc(fqName, help string, variableLabels []string) *prometheus.Desc {
+	key := fqName + "\xff" + help + "\xff" + strings.Join(variableLabels, "\xff")
+
+	descsMu.Lock()
+	defer descsMu.Unlock()
+	if d, found := byName[key]; found {
+		return d
+	}
+
+	d := prometheus.NewDesc(fqName, help, variableLabels, nil)
+	byName[key] = d
+	descs[d] = DescInfo{
+		FqName:         fqName,
+		Help:           help,
+		VariableLabels: append([]string{}, variableLabels...),
+	}
+
+	return d
+}
+
+// LookupDesc gets the parameters of a descriptor created by NewDesc
+func LookupDesc(d *prometheus.Desc) (DescInfo, bool) {
+	descsMu.RLock()
+	defer descsMu.RUnlock()
+
+	info, found := descs[d]
+	return info, found
+}
+
+// LabelNames gets all variable label names used by descriptors created by NewDesc
+func LabelNames() map[string]bool {
+	descsMu.RLock()
+	defer descsMu.RUnlock()
+
+	names := make(map[string]bool)
+	for _, info := range descs {
+		for _, l := range info.VariableLabels {
+			names[l] = true
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,220 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// buildSuffixRegexp matches what follows the version numbers, e.g. the build of 8.6.0.4_74969
+var buildSuffixRegexp = regexp.MustCompile(`[_\-\s(].*$`)
+
+// UnsupportedDesc is reported for every parser a device has no match for
+var UnsupportedDesc *prometheus.Desc
+
+func init() {
+	UnsupportedDesc = NewDesc("aruba_parser_unsupported", "No parser is available for the OS type and firmware version of the device", []string{"target", "collector", "parser", "os_type", "version"})
+}
+
+// Parser is a command and the function parsing its output for an OS type and a range of firmware versions
+type Parser struct {
+	OSType string
+	// MinVersion is the first firmware version the parser supports, empty for no lower bound
+	MinVersion string
+	// MaxVersion is the first firmware version the parser does not support anymore, empty for no upper bound
+	MaxVersion string
+	Commands   []string
+	Parse      func(output string) (interface{}, error)
+
+	min, max []int
+}
+
+// ParserCollector is implemented by collectors which parse the command outputs with a parser registry
+type ParserCollector interface {
+	RPCCollector
+
+	// Parsers gets the parsers of the collector
+	Parsers() *Parsers
+}
+
+// Parsers holds the parsers of a collector by name
+type Parsers struct {
+	collector string
+	parsers   map[string][]*Parser
+}
+
+// NewParsers creates an empty parser registry for a collector
+func NewParsers(collector string) *Parsers {
+	return &Parsers{
+		collector: collector,
+		parsers:   make(map[string][]*Parser),
+	}
+}
+
+// Register adds a parser. It panics if a version can not be parsed, since parsers are registered at startup.
+func (r *Parsers) Register(name string, p *Parser) {
+	var err error
+	p.min, err = parseVersion(p.MinVersion)
+	if err != nil {
+		panic(fmt.Sprintf("parser %s/%s: %v", r.collector, name, err))
+	}
+	p.max, err = parseVersion(p.MaxVersion)
+	if err != nil {
+		panic(fmt.Sprintf("parser %s/%s: %v", r.collector, name, err))
+	}
+
+	r.parsers[name] = append(r.parsers[name], p)
+}
+
+// Names gets the names of all registered parsers
+func (r *Parsers) Names() []string {
+	names := make([]string, 0, len(r.parsers))
+	for name := range r.parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Registered gets all parsers registered under a name
+func (r *Parsers) Registered(name string) []*Parser {
+	return r.parsers[name]
+}
+
+// Supports checks if a parser is registered for an OS type in any firmware version. Collectors skip parsers
+// which do not apply to an OS type at all (e.g. wireless on switches) instead of reporting them as unsupported.
+func (r *Parsers) Supports(name, osType string) bool {
+	for _, p := range r.parsers[name] {
+		if p.OSType == osType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve finds the best parser for an OS type and firmware version. Of all parsers whose version range
+// contains the version, the one with the highest minimum version wins, then the one with the lowest maximum.
+// If the version is unknown (e.g. the OS type was configured), only parsers without a version range match.
+func (r *Parsers) Resolve(name, osType, version string) (*Parser, bool) {
+	v, err := parseVersion(version)
+	if err != nil {
+		log.Debugf("could not parse firmware version %q: %v", version, err)
+		v = nil
+	}
+
+	var best *Parser
+	for _, p := range r.parsers[name] {
+		if p.OSType != osType || !p.matches(v) {
+			continue
+		}
+		if best == nil || compareVersions(p.min, best.min) > 0 || (compareVersions(p.min, best.min) == 0 && compareMax(p.max, best.max) < 0) {
+			best = p
+		}
+	}
+
+	return best, best != nil
+}
+
+// Parser resolves the parser for the device of the client. If there is none,
+// it is reported by the unsupported metric and an error is returned.
+func (r *Parsers) Parser(name string, client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) (*Parser, error) {
+	p, found := r.Resolve(name, client.OSType, client.Version)
+	if !found {
+		ch <- prometheus.MustNewConstMetric(UnsupportedDesc, prometheus.GaugeValue, 1, append(labelValues, r.collector, name, client.OSType, client.Version)...)
+		return nil, fmt.Errorf("'%s' is not implemented for %s %s", name, client.OSType, client.Version)
+	}
+
+	log.Debugf("using %s parser %s for %s %s (versions %q to %q)", r.collector, name, client.OSType, client.Version, p.MinVersion, p.MaxVersion)
+	return p, nil
+}
+
+// Run resolves the parser for the device of the client, runs its commands and parses the output
+func Run(r *Parsers, name string, client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) (interface{}, error) {
+	p, err := r.Parser(name, client, ch, labelValues)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := client.RunCommand(p.Commands)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Parse(out)
+}
+
+func (p *Parser) matches(v []int) bool {
+	if v == nil {
+		return p.min == nil && p.max == nil
+	}
+
+	return compareVersions(v, p.min) >= 0 && (p.max == nil || compareVersions(v, p.max) < 0)
+}
+
+// parseVersion splits a version like 10.04.0030 into its numbers. A prefix like FL. and a build suffix
+// like _74969 or -FIPS are ignored.
+func parseVersion(version string) ([]int, error) {
+	if version == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(buildSuffixRegexp.ReplaceAllString(version, ""), ".")
+	if len(parts) > 1 && strings.Trim(parts[0], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		parts = parts[1:]
+	}
+
+	v := make([]int, len(parts))
+	for i, s := range parts {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		v[i] = n
+	}
+
+	return v, nil
+}
+
+// compareVersions compares two versions number by number, missing numbers are 0
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// compareMax compares two upper bounds, where no bound is higher than any version
+func compareMax(a, b []int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return compareVersions(a, b)
+	}
+}
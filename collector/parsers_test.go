@@ -0,0 +1,254 @@
+package collector
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []int
+		err     bool
+	}{
+		{version: "", want: nil},
+		{version: "10.04.0030", want: []int{10, 4, 30}},
+		{version: "10.04", want: []int{10, 4}},
+		{version: "FL.16.10.0012", want: []int{16, 10, 12}},
+		{version: "KB.16.11.0001", want: []int{16, 11, 1}},
+		{version: "8.6.0.4", want: []int{8, 6, 0, 4}},
+		{version: "16", want: []int{16}},
+		{version: "FL", err: true},
+		{version: "fl.16.10", err: true},
+		{version: "10.x", err: true},
+		{version: "10..4", err: true},
+		{version: "10.04-rc1", want: []int{10, 4}},
+		{version: "8.6.0.4-FIPS", want: []int{8, 6, 0, 4}},
+		{version: "8.6.0.4_74969", want: []int{8, 6, 0, 4}},
+		{version: "GL.10.10.0002 (build 74969)", want: []int{10, 10, 2}},
+		{version: "_74969", err: true},
+		{version: "8.6.x_74969", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseVersion(test.version)
+		if (err != nil) != test.err {
+			t.Errorf("%q: got error %v, want error %v", test.version, err, test.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.version, got, test.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b []int
+		want int
+	}{
+		{a: nil, b: nil, want: 0},
+		{a: []int{10, 4}, b: []int{10, 4}, want: 0},
+		{a: []int{10, 4}, b: []int{10, 4, 0}, want: 0},
+		{a: nil, b: []int{0, 0}, want: 0},
+		{a: nil, b: []int{1}, want: -1},
+		{a: []int{10, 4, 1}, b: []int{10, 4}, want: 1},
+		{a: []int{9, 10}, b: []int{10}, want: -1},
+		{a: []int{10, 10}, b: []int{10, 9, 99}, want: 1},
+		{a: []int{16, 10, 12}, b: []int{16, 11}, want: -1},
+	}
+
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("%v, %v: got %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := compareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("%v, %v: got %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestCompareMax(t *testing.T) {
+	tests := []struct {
+		a, b []int
+		want int
+	}{
+		{a: nil, b: nil, want: 0},
+		{a: nil, b: []int{10}, want: 1},
+		{a: []int{10}, b: nil, want: -1},
+		{a: []int{10, 4}, b: []int{10, 8}, want: -1},
+	}
+
+	for _, test := range tests {
+		if got := compareMax(test.a, test.b); got != test.want {
+			t.Errorf("%v, %v: got %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+// parser creates a parser whose command identifies it in the results
+func parser(osType, min, max, id string) *Parser {
+	return &Parser{OSType: osType, MinVersion: min, MaxVersion: max, Commands: []string{id}}
+}
+
+func TestResolve(t *testing.T) {
+	r := NewParsers("test")
+	r.Register("a", parser(rpc.ArubaCXSwitch, "", "", "any"))
+	r.Register("a", parser(rpc.ArubaCXSwitch, "", "10.04", "up to 10.04"))
+	r.Register("a", parser(rpc.ArubaCXSwitch, "10.04", "", "from 10.04"))
+	r.Register("a", parser(rpc.ArubaCXSwitch, "10.04", "10.08", "10.04 to 10.08"))
+	r.Register("a", parser(rpc.ArubaCXSwitch, "10.06", "10.10", "10.06 to 10.10"))
+	r.Register("a", parser(rpc.ArubaSwitch, "KB.16.10", "", "aos-s from 16.10"))
+	r.Register("b", parser(rpc.ArubaCXSwitch, "10.04", "", "b from 10.04"))
+	r.Register("c", parser(rpc.ArubaController, "", "", "controller"))
+	r.Register("c", parser(rpc.ArubaController, "8.6", "", "controller from 8.6"))
+
+	tests := []struct {
+		name    string
+		osType  string
+		version string
+		want    string
+	}{
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.03.0010", want: "up to 10.04"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.04.0001", want: "10.04 to 10.08"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.04", want: "10.04 to 10.08"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.07.0020", want: "10.06 to 10.10"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.08", want: "10.06 to 10.10"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.10.1000", want: "from 10.04"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "GL.10.12.0001", want: "from 10.04"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "", want: "any"},
+		{name: "a", osType: rpc.ArubaCXSwitch, version: "10.x", want: "any"},
+		{name: "a", osType: rpc.ArubaSwitch, version: "WC.16.10.0012", want: "aos-s from 16.10"},
+		{name: "a", osType: rpc.ArubaSwitch, version: "WC.16.09.0012"},
+		{name: "a", osType: rpc.ArubaSwitch, version: ""},
+		{name: "a", osType: rpc.ArubaInstant, version: "8.6.0.4"},
+		{name: "b", osType: rpc.ArubaCXSwitch, version: "10.05", want: "b from 10.04"},
+		{name: "b", osType: rpc.ArubaCXSwitch, version: "10.03"},
+		{name: "b", osType: rpc.ArubaCXSwitch, version: "unknown"},
+		{name: "c", osType: rpc.ArubaCXSwitch, version: "10.05"},
+		{name: "c", osType: rpc.ArubaController, version: "8.6.0.4_74969", want: "controller from 8.6"},
+		{name: "c", osType: rpc.ArubaController, version: "8.5.0.11_80139", want: "controller"},
+		{name: "c", osType: rpc.ArubaController, version: "8.x_1", want: "controller"},
+		{name: "d", osType: rpc.ArubaCXSwitch, version: "10.05"},
+	}
+
+	for _, test := range tests {
+		p, found := r.Resolve(test.name, test.osType, test.version)
+		got := ""
+		if found {
+			got = p.Commands[0]
+		}
+		if got != test.want {
+			t.Errorf("%s %s %q: got %q, want %q", test.name, test.osType, test.version, got, test.want)
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	r := NewParsers("test")
+	r.Register("a", parser(rpc.ArubaCXSwitch, "10.04", "", "from 10.04"))
+
+	if !r.Supports("a", rpc.ArubaCXSwitch) {
+		t.Error("a should be supported on CX, even if the version does not match")
+	}
+	if r.Supports("a", rpc.ArubaSwitch) || r.Supports("b", rpc.ArubaCXSwitch) {
+		t.Error("unexpected support")
+	}
+}
+
+func TestRegisterInvalidVersion(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an invalid version")
+		}
+	}()
+
+	NewParsers("test").Register("a", parser(rpc.ArubaCXSwitch, "10.x", "", "invalid"))
+}
+
+func TestParserUnsupported(t *testing.T) {
+	r := NewParsers("test")
+	r.Register("a", parser(rpc.ArubaCXSwitch, "10.04", "", "from 10.04"))
+
+	client := rpc.NewClientForConnection(nil, "switch1", "")
+	client.OSType = rpc.ArubaCXSwitch
+	client.Version = "10.03.0010"
+
+	ch := make(chan prometheus.Metric, 1)
+	_, err := r.Parser("a", client, ch, []string{"switch1"})
+	if err == nil || err.Error() != "'a' is not implemented for ArubaCXSwitch 10.03.0010" {
+		t.Errorf("got error %v", err)
+	}
+
+	var m dto.Metric
+	err = (<-ch).Write(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := []string{}
+	for _, l := range m.GetLabel() {
+		labels = append(labels, l.GetName()+"="+l.GetValue())
+	}
+	if got, want := strings.Join(labels, ","), "collector=test,os_type=ArubaCXSwitch,parser=a,target=switch1,version=10.03.0010"; got != want {
+		t.Errorf("got labels %s, want %s", got, want)
+	}
+}
+
+type fakeConnection struct {
+	cmds []string
+}
+
+func (f *fakeConnection) RunCommand(cmds []string) (string, error) {
+	f.cmds = append(f.cmds, cmds...)
+	return strings.Join(cmds, "\n"), nil
+}
+
+func TestRun(t *testing.T) {
+	r := NewParsers("test")
+	r.Register("a", &Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show a", "show b"},
+		Parse:    func(out string) (interface{}, error) { return strings.Split(out, "\n"), nil },
+	})
+	r.Register("fails", &Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show c"},
+		Parse:    func(out string) (interface{}, error) { return nil, errors.New("no output") },
+	})
+
+	conn := &fakeConnection{}
+	client := rpc.NewClientForConnection(conn, "switch1", "")
+	client.OSType = rpc.ArubaCXSwitch
+	ch := make(chan prometheus.Metric, 1)
+
+	parsed, err := Run(r, "a", client, ch, []string{"switch1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"show a", "show b"}; !reflect.DeepEqual(parsed, want) {
+		t.Errorf("got %v, want %v", parsed, want)
+	}
+
+	_, err = Run(r, "fails", client, ch, []string{"switch1"})
+	if err == nil || err.Error() != "no output" {
+		t.Errorf("got error %v", err)
+	}
+
+	_, err = Run(r, "b", client, ch, []string{"switch1"})
+	if err == nil {
+		t.Error("expected an error for a parser which is not registered")
+	}
+	if want := []string{"show a", "show b", "show c"}; !reflect.DeepEqual(conn.cmds, want) {
+		t.Errorf("got commands %v, want %v", conn.cmds, want)
+	}
+	if len(ch) != 1 {
+		t.Errorf("got %d metrics, want the unsupported metric", len(ch))
+	}
+}
@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/slashdoom/aruba_exporter/config"
+)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]func() RPCCollector)
+)
+
+// Register makes a collector available under a name. The name is also the feature
+// used to enable or disable the collector in the config and by the -<name>.enabled flag.
+// Collectors register themselves in the init function of their package.
+func Register(name string, enabledByDefault bool, newCollector func() RPCCollector) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, found := factories[name]; found {
+		panic("collector " + name + " is already registered")
+	}
+	factories[name] = newCollector
+	config.RegisterFeature(name, enabledByDefault)
+}
+
+// Names gets the names of all registered collectors
+func Names() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New creates a collector by name
+func New(name string) (RPCCollector, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	newCollector, found := factories[name]
+	if !found {
+		return nil, false
+	}
+
+	return newCollector(), true
+}
@@ -5,15 +5,29 @@ import (
 	"github.com/slashdoom/aruba_exporter/config"
 	"github.com/slashdoom/aruba_exporter/connector"
 	
-	"github.com/slashdoom/aruba_exporter/interfaces"
-	"github.com/slashdoom/aruba_exporter/system"
-	"github.com/slashdoom/aruba_exporter/wireless"
-	"github.com/slashdoom/aruba_exporter/environment"
+	"github.com/slashdoom/aruba_exporter/custom"
+
+	// collectors register themselves when their package is loaded
+	_ "github.com/slashdoom/aruba_exporter/bgp"
+	_ "github.com/slashdoom/aruba_exporter/environment"
+	_ "github.com/slashdoom/aruba_exporter/interfaces"
+	_ "github.com/slashdoom/aruba_exporter/lag"
+	_ "github.com/slashdoom/aruba_exporter/lldp"
+	_ "github.com/slashdoom/aruba_exporter/optics"
+	_ "github.com/slashdoom/aruba_exporter/ospf"
+	_ "github.com/slashdoom/aruba_exporter/poe"
+	_ "github.com/slashdoom/aruba_exporter/routes"
+	_ "github.com/slashdoom/aruba_exporter/stacking"
+	_ "github.com/slashdoom/aruba_exporter/stp"
+	_ "github.com/slashdoom/aruba_exporter/system"
+	_ "github.com/slashdoom/aruba_exporter/vsx"
+	_ "github.com/slashdoom/aruba_exporter/wireless"
 )
 
 type collectors struct {
 	collectors map[string]collector.RPCCollector
 	devices    map[string][]collector.RPCCollector
+	names      map[collector.RPCCollector]string
 	cfg        *config.Config
 }
 
@@ -21,6 +35,7 @@ func collectorsForDevices(devices []*connector.Device, cfg *config.Config) *coll
 	c := &collectors{
 		collectors: make(map[string]collector.RPCCollector),
 		devices:    make(map[string][]collector.RPCCollector),
+		names:      make(map[collector.RPCCollector]string),
 		cfg:        cfg,
 	}
 
@@ -32,17 +47,27 @@ func collectorsForDevices(devices []*connector.Device, cfg *config.Config) *coll
 }
 
 func (c *collectors) initCollectorsForDevice(device *connector.Device) {
-	f := c.cfg.FeaturesForDevice(device.Host)
-	
-	c.devices[device.Host] = make([]collector.RPCCollector, 0)
-	c.addCollectorIfEnabledForDevice(device, "system", f.System, system.NewCollector)
-	c.addCollectorIfEnabledForDevice(device, "interfaces", f.Interfaces, interfaces.NewCollector)
-    c.addCollectorIfEnabledForDevice(device, "wireless", f.Wireless, wireless.NewCollector)
-	c.addCollectorIfEnabledForDevice(device, "environment", f.Environment, environment.NewCollector)
+	host := device.DeviceConfig.Host
+
+	c.devices[host] = make([]collector.RPCCollector, 0)
+	for _, name := range collector.Names() {
+		name := name
+		c.addCollectorIfEnabledForDevice(device, name, c.cfg.FeatureEnabled(host, name), func() collector.RPCCollector {
+			col, _ := collector.New(name)
+			return col
+		})
+	}
+
+	for _, cc := range c.cfg.CustomCollectors {
+		cc := cc
+		c.addCollectorIfEnabledForDevice(device, cc.Name, c.cfg.FeatureEnabled(host, cc.Name), func() collector.RPCCollector {
+			return custom.NewCollector(cc)
+		})
+	}
 }
 
-func (c *collectors) addCollectorIfEnabledForDevice(device *connector.Device, key string, enabled *bool, newCollector func() collector.RPCCollector) {
-	if !*enabled {
+func (c *collectors) addCollectorIfEnabledForDevice(device *connector.Device, key string, enabled bool, newCollector func() collector.RPCCollector) {
+	if !enabled {
 		return
 	}
 
@@ -50,9 +75,10 @@ func (c *collectors) addCollectorIfEnabledForDevice(device *connector.Device, ke
 	if !found {
 		col = newCollector()
 		c.collectors[key] = col
+		c.names[col] = key
 	}
 
-	c.devices[device.Host] = append(c.devices[device.Host], col)
+	c.devices[device.DeviceConfig.Host] = append(c.devices[device.DeviceConfig.Host], col)
 }
 
 func (c *collectors) allEnabledCollectors() []collector.RPCCollector {
@@ -68,10 +94,15 @@ func (c *collectors) allEnabledCollectors() []collector.RPCCollector {
 }
 
 func (c *collectors) collectorsForDevice(device *connector.Device) []collector.RPCCollector {
-	cols, found := c.devices[device.Host]
+	cols, found := c.devices[device.DeviceConfig.Host]
 	if !found {
 		return []collector.RPCCollector{}
 	}
 
 	return cols
 }
+
+// name gets the name a collector is enabled by (e.g. interfaces)
+func (c *collectors) name(col collector.RPCCollector) string {
+	return c.names[col]
+}
@@ -4,64 +4,88 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
-
-	"gopkg.in/yaml.v2"
 )
 
 // Config represents the configuration for the exporter
 type Config struct {
-	Level         string          `yaml:"level,omitempty"`
-	LegacyCiphers bool            `yaml:"legacy_ciphers,omitempty"`
-	Timeout       int             `yaml:"timeout,omitempty"`
-	BatchSize     int             `yaml:"batch_size,omitempty"`
-	Username      string          `yaml:"username,omitempty"`
-	Password      string          `yaml:"password,omitempty"`
-	KeyFile       string          `yaml:"key_file,omitempty"`
-	Devices       []*DeviceConfig `yaml:"devices,omitempty"`
-	Features      *FeatureConfig  `yaml:"features,omitempty"`
+	Level             string                   `yaml:"level,omitempty"`
+	LegacyCiphers     bool                     `yaml:"legacy_ciphers,omitempty"`
+	Timeout           int                      `yaml:"timeout,omitempty"`
+	BatchSize         int                      `yaml:"batch_size,omitempty"`
+	CommandDelay      int                      `yaml:"command_delay,omitempty"`
+	Port              int                      `yaml:"port,omitempty"`
+	AddressFamily     string                   `yaml:"address_family,omitempty"`
+	Username          string                   `yaml:"username,omitempty"`
+	Password          Secret                   `yaml:"password,omitempty"`
+	PasswordFile      string                   `yaml:"password_file,omitempty"`
+	PasswordCommand   string                   `yaml:"password_command,omitempty"`
+	KeyFile           string                   `yaml:"key_file,omitempty"`
+	Devices           []*DeviceConfig          `yaml:"devices,omitempty"`
+	Features          FeatureConfig            `yaml:"features,omitempty"`
+	Labels            map[string]string        `yaml:"labels,omitempty"`
+	Groups            map[string]*GroupConfig  `yaml:"groups,omitempty"`
+	Inventory         *InventoryConfig         `yaml:"inventory,omitempty"`
+	CustomCollectors  []*CustomCollectorConfig `yaml:"custom_collectors,omitempty"`
+	TemplateDir       string                   `yaml:"template_directory,omitempty"`
+	RecordTranscripts bool                     `yaml:"record_transcripts,omitempty"`
+	Transcripts       *TranscriptsConfig       `yaml:"transcripts,omitempty"`
 }
 
 // DeviceConfig is the config representation of 1 device
 type DeviceConfig struct {
-	Host          string         `yaml:"host"`
-	Username      *string        `yaml:"username,omitempty"`
-	Password      *string        `yaml:"password,omitempty"`
-	KeyFile       *string        `yaml:"key_file,omitempty"`
-	LegacyCiphers *bool          `yaml:"legacy_ciphers,omitempty"`
-	Timeout       *int           `yaml:"timeout,omitempty"`
-	BatchSize     *int           `yaml:"batch_size,omitempty"`
-	Features      *FeatureConfig `yaml:"features,omitempty"`
+	Host              string            `yaml:"host"`
+	Port              *int              `yaml:"port,omitempty"`
+	AddressFamily     *string           `yaml:"address_family,omitempty"`
+	Username          *string           `yaml:"username,omitempty"`
+	Password          *Secret           `yaml:"password,omitempty"`
+	PasswordFile      *string           `yaml:"password_file,omitempty"`
+	PasswordCommand   *string           `yaml:"password_command,omitempty"`
+	KeyFile           *string           `yaml:"key_file,omitempty"`
+	LegacyCiphers     *bool             `yaml:"legacy_ciphers,omitempty"`
+	Timeout           *int              `yaml:"timeout,omitempty"`
+	BatchSize         *int              `yaml:"batch_size,omitempty"`
+	CommandDelay      *int              `yaml:"command_delay,omitempty"`
+	Features          FeatureConfig     `yaml:"features,omitempty"`
+	Group             string            `yaml:"group,omitempty"`
+	Labels            map[string]string `yaml:"labels,omitempty"`
+	OSType            string            `yaml:"os_type,omitempty"`
+	RecordTranscripts *bool             `yaml:"record_transcripts,omitempty"`
+}
+
+// GroupConfig holds settings shared by all devices of a group
+type GroupConfig struct {
+	Labels map[string]string `yaml:"labels,omitempty"`
 }
 
-// FeatureConfig is the list of collectors enabled or disabled
-type FeatureConfig struct {
-	BGP         *bool `yaml:"bgp,omitempty"`
-	Environment *bool `yaml:"environment,omitempty"`
-	Interfaces  *bool `yaml:"interfaces,omitempty"`
-	Optics      *bool `yaml:"optics,omitempty"`
-	System      *bool `yaml:"system,omitempty"`
-	Wireless	*bool `yaml:"wireless,omitempty"`
+// InventoryConfig configures the directories devices are discovered from
+type InventoryConfig struct {
+	Directories     []string `yaml:"directories"`
+	RefreshInterval int      `yaml:"refresh_interval,omitempty"`
 }
 
 // New creates a new config
 func New() *Config {
 	c := &Config{
-		Features: &FeatureConfig{},
+		Features: FeatureConfig{},
 	}
 	c.setDefaultValues()
 
 	return c
 }
 
-// Load loads a config from reader
+// Load loads a config from reader. Unknown fields and invalid values are rejected.
 func Load(reader io.Reader) (*Config, error) {
 	b, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
-	c := New()
-	err = yaml.Unmarshal(b, c)
+	c, problems := parse(b)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	err = c.ResolveSecrets()
 	if err != nil {
 		return nil, err
 	}
@@ -74,20 +98,8 @@ func (c *Config) setDefaultValues() {
 	c.LegacyCiphers = false
 	c.Timeout = 5
 	c.BatchSize = 10000
-
-	f := c.Features
-	bgp := true
-	f.BGP = &bgp
-	environment := true
-	f.Environment = &environment
-	interfaces := true
-	f.Interfaces = &interfaces
-	optics := true
-	f.Optics = &optics
-	system := true
-	f.System = &system
-	wireless := true
-	f.Wireless = &wireless
+	c.CommandDelay = 1000
+	c.Port = 22
 }
 
 // DevicesFromTargets creates devices configs from targets list
@@ -102,15 +114,28 @@ func (c *Config) DevicesFromTargets(sshHosts string) {
 	}
 }
 
-// FeaturesForDevice gets the feature set configured for a device
-func (c *Config) FeaturesForDevice(host string) *FeatureConfig {
-	d := c.findDeviceConfig(host)
+// LabelsForDevice gets the custom labels of a device merged with the labels of its group and the global labels
+func (c *Config) LabelsForDevice(d *DeviceConfig) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range c.Labels {
+		labels[k] = v
+	}
+
+	if d == nil {
+		return labels
+	}
+
+	if g, found := c.Groups[d.Group]; found && g != nil {
+		for k, v := range g.Labels {
+			labels[k] = v
+		}
+	}
 
-	if d != nil && d.Features != nil {
-		return d.Features
+	for k, v := range d.Labels {
+		labels[k] = v
 	}
 
-	return c.Features
+	return labels
 }
 
 func (c *Config) findDeviceConfig(host string) *DeviceConfig {
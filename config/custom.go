@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/textfsm"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OS types commands of custom collectors can be defined for, these must match the OS types in package rpc
+var osTypes = []string{"ArubaInstant", "ArubaController", "ArubaSwitch", "ArubaCXSwitch"}
+
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// CustomCollectorConfig defines a collector which runs a command and extracts metrics from its output
+type CustomCollectorConfig struct {
+	Name     string                `yaml:"name"`
+	Commands map[string]string     `yaml:"commands"`
+	Regex    string                `yaml:"regex,omitempty"`
+	Table    *TableConfig          `yaml:"table,omitempty"`
+	Template string                `yaml:"template,omitempty"`
+	Metrics  []*CustomMetricConfig `yaml:"metrics"`
+
+	// template is parsed once when the config is loaded
+	template *textfsm.Template
+}
+
+// TableConfig defines how to split the output of a command into rows and columns
+type TableConfig struct {
+	Columns   []string `yaml:"columns"`
+	Start     string   `yaml:"start,omitempty"`
+	End       string   `yaml:"end,omitempty"`
+	Separator string   `yaml:"separator,omitempty"`
+}
+
+// CustomMetricConfig defines a metric read from the columns extracted by a custom collector
+type CustomMetricConfig struct {
+	Name     string             `yaml:"name"`
+	Help     string             `yaml:"help"`
+	Type     string             `yaml:"type,omitempty"`
+	Value    string             `yaml:"value"`
+	ValueMap map[string]float64 `yaml:"value_map,omitempty"`
+	Labels   []string           `yaml:"labels,omitempty"`
+}
+
+// Columns gets the names of the columns a custom collector extracts
+func (c *CustomCollectorConfig) Columns() []string {
+	if c.Table != nil {
+		return c.Table.Columns
+	}
+
+	if c.Template != "" {
+		if c.template == nil {
+			return nil
+		}
+		return c.template.Header()
+	}
+
+	re, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return nil
+	}
+
+	columns := []string{}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
+
+// TextFSM gets the template parsed when the config was loaded, nil if the collector does not use a template
+func (c *CustomCollectorConfig) TextFSM() *textfsm.Template {
+	return c.template
+}
+
+// TemplatePath resolves the path of a TextFSM template relative to the template directory
+func (c *Config) TemplatePath(name string) string {
+	if c.TemplateDir == "" || filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(c.TemplateDir, name)
+}
+
+func (c *Config) validateCustomCollectors(root *yaml.Node) []Problem {
+	problems := []Problem{}
+	names := make(map[string]bool)
+
+	nodes := sequenceItems(mappingValue(documentNode(root), "custom_collectors"))
+	for i, cc := range c.CustomCollectors {
+		var n *yaml.Node
+		if i < len(nodes) {
+			n = nodes[i]
+		}
+		line := nodeLine(n)
+		add := func(format string, args ...interface{}) {
+			problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("custom collector %q: ", cc.Name) + fmt.Sprintf(format, args...)})
+		}
+
+		switch {
+		case cc.Name == "":
+			add("missing name")
+		case isRegisteredFeature(cc.Name):
+			add("name is already used by a built-in collector")
+		case names[cc.Name]:
+			add("duplicate name")
+		}
+		names[cc.Name] = true
+
+		if len(cc.Commands) == 0 {
+			add("no commands defined")
+		}
+		for _, osType := range sortedKeys(cc.Commands) {
+			if !isKnownOSType(osType) {
+				add("unknown OS type %q in commands, must be one of %s", osType, strings.Join(osTypes, ", "))
+			}
+		}
+
+		extractors := 0
+		for _, set := range []bool{cc.Regex != "", cc.Table != nil, cc.Template != ""} {
+			if set {
+				extractors++
+			}
+		}
+		if extractors != 1 {
+			add("exactly one of regex, table and template must be set")
+		}
+		if cc.Regex != "" {
+			_, err := regexp.Compile(cc.Regex)
+			if err != nil {
+				add("invalid regex: %v", err)
+			}
+		}
+		if cc.Table != nil {
+			if len(cc.Table.Columns) == 0 {
+				add("table has no columns")
+			}
+			for _, re := range []string{cc.Table.Start, cc.Table.End, cc.Table.Separator} {
+				_, err := regexp.Compile(re)
+				if err != nil {
+					add("invalid regex in table: %v", err)
+				}
+			}
+		}
+
+		if cc.Template != "" {
+			t, err := textfsm.ParseFile(cc.Template)
+			if err != nil {
+				add("%v", err)
+				continue
+			}
+			cc.template = t
+		}
+
+		columns := make(map[string]bool)
+		for _, col := range cc.Columns() {
+			columns[col] = true
+		}
+
+		if len(cc.Metrics) == 0 {
+			add("no metrics defined")
+		}
+		for _, m := range cc.Metrics {
+			if !metricNameRegexp.MatchString(m.Name) {
+				add("invalid metric name %q", m.Name)
+			}
+			switch m.Type {
+			case "", "gauge", "counter", "untyped":
+			default:
+				add("metric %s: invalid type %q, must be gauge, counter or untyped", m.Name, m.Type)
+			}
+			if !columns[m.Value] {
+				add("metric %s: value column %q is not extracted", m.Name, m.Value)
+			}
+			for _, l := range m.Labels {
+				if !columns[l] {
+					add("metric %s: label column %q is not extracted", m.Name, l)
+				}
+				if !IsValidLabelName(l) || l == "target" {
+					add("metric %s: invalid label name %q", m.Name, l)
+				}
+			}
+		}
+	}
+
+	problems = append(problems, checkFeatureNames(mappingValue(documentNode(root), "features"), names)...)
+	for _, n := range sequenceItems(mappingValue(documentNode(root), "devices")) {
+		problems = append(problems, checkFeatureNames(mappingValue(n, "features"), names)...)
+	}
+
+	return problems
+}
+
+func checkFeatureNames(node *yaml.Node, custom map[string]bool) []Problem {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	problems := []Problem{}
+	for i := 0; i < len(node.Content); i += 2 {
+		k := node.Content[i]
+		if !isRegisteredFeature(k.Value) && !custom[k.Value] {
+			problems = append(problems, Problem{Line: k.Line, Message: fmt.Sprintf("unknown feature %q", k.Value)})
+		}
+	}
+
+	return problems
+}
+
+func isKnownOSType(osType string) bool {
+	for _, t := range osTypes {
+		if t == osType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
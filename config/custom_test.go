@@ -0,0 +1,90 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const macTemplate = `Value Required VLAN (\d+)
+Value COUNT (\d+)
+
+Start
+  ^\s*${VLAN}\s+${COUNT}\s*$$ -> Record
+`
+
+func TestLoadParsesTemplateOnce(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "mac_count.textfsm"), []byte(macTemplate), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(strings.NewReader(`template_directory: ` + dir + `
+custom_collectors:
+  - name: mac
+    commands:
+      ArubaSwitch: show mac-address count
+    template: mac_count.textfsm
+    metrics:
+      - name: aruba_mac_count
+        help: MAC addresses per VLAN
+        value: COUNT
+        labels: [VLAN]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cc := c.CustomCollectors[0]
+	if want := filepath.Join(dir, "mac_count.textfsm"); cc.Template != want {
+		t.Errorf("got template path %s, want %s", cc.Template, want)
+	}
+	if cc.TextFSM() == nil {
+		t.Fatal("template was not parsed")
+	}
+	if got := cc.Columns(); !reflect.DeepEqual(got, []string{"VLAN", "COUNT"}) {
+		t.Errorf("got columns %v", got)
+	}
+}
+
+func TestCheckTemplateColumns(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "mac_count.textfsm"), []byte(macTemplate), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := Check(strings.NewReader(`template_directory: ` + dir + `
+custom_collectors:
+  - name: mac
+    commands:
+      ArubaSwitch: show mac-address count
+    template: mac_count.textfsm
+    metrics:
+      - name: aruba_mac_count
+        help: MAC addresses per VLAN
+        value: MACS
+  - name: missing
+    commands:
+      ArubaSwitch: show mac-address count
+    template: missing.textfsm
+    metrics:
+      - name: aruba_missing
+        help: not loaded
+        value: COUNT
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{Line: 3, Message: `custom collector "mac": metric aruba_mac_count: value column "MACS" is not extracted`},
+		{Line: 11, Message: `custom collector "missing": open ` + filepath.Join(dir, "missing.textfsm") + `: no such file or directory`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("got %v, want %v", problems, want)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	featuresMu sync.RWMutex
+	features   = make(map[string]bool)
+)
+
+// FeatureConfig enables or disables collectors by name
+type FeatureConfig map[string]bool
+
+// RegisterFeature makes a feature known to the config, so it can be enabled or disabled
+func RegisterFeature(name string, enabledByDefault bool) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+
+	features[name] = enabledByDefault
+}
+
+// Features gets the names of all registered features
+func Features() []string {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// FeatureDefault gets if a registered feature is enabled by default
+func FeatureDefault(name string) bool {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+
+	return features[name]
+}
+
+func isRegisteredFeature(name string) bool {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+
+	_, found := features[name]
+	return found
+}
+
+// FeatureEnabled checks if a feature is enabled for a device. A setting for the device
+// takes precedence over the global setting, which takes precedence over the default.
+// Custom collectors are enabled by default.
+func (c *Config) FeatureEnabled(host, name string) bool {
+	d := c.findDeviceConfig(host)
+	if d != nil {
+		if enabled, found := d.Features[name]; found {
+			return enabled
+		}
+	}
+
+	if enabled, found := c.Features[name]; found {
+		return enabled
+	}
+
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+	if enabled, found := features[name]; found {
+		return enabled
+	}
+
+	for _, cc := range c.CustomCollectors {
+		if cc.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
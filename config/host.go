@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Address families which can be preferred when a host name resolves to several addresses
+const (
+	AddressFamilyIPv4 string = "ipv4"
+	AddressFamilyIPv6 string = "ipv6"
+)
+
+// SplitHostPort splits a target into host and port. IPv6 addresses can be given with or
+// without brackets, but a port can only be appended to a bracketed address (e.g. [2001:db8::1]:2222).
+// The port is empty if the target does not contain one.
+func SplitHostPort(target string) (host, port string, err error) {
+	switch {
+	case strings.HasPrefix(target, "["):
+		if strings.HasSuffix(target, "]") {
+			host = target[1 : len(target)-1]
+		} else {
+			host, port, err = net.SplitHostPort(target)
+			if err != nil {
+				return "", "", err
+			}
+			if port == "" {
+				return "", "", fmt.Errorf("missing port after %q", host)
+			}
+		}
+		if !isIPv6(host) {
+			return "", "", fmt.Errorf("%q is not an IPv6 address", host)
+		}
+	case strings.Count(target, ":") > 1:
+		if !isIPv6(target) {
+			return "", "", fmt.Errorf("%q is not an IPv6 address, use [address]:port to add a port", target)
+		}
+		host = target
+	case strings.Contains(target, ":"):
+		host, port, err = net.SplitHostPort(target)
+		if err != nil {
+			return "", "", err
+		}
+		if port == "" {
+			return "", "", fmt.Errorf("missing port after %q", host)
+		}
+	default:
+		host = target
+	}
+
+	if host == "" {
+		return "", "", fmt.Errorf("missing host name")
+	}
+
+	if port != "" {
+		_, err = parsePort(port)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return host, port, nil
+}
+
+// AddressForDevice gets the host and port to connect to for a device. A port in the
+// host field takes precedence over the port of the device and the global port.
+func (c *Config) AddressForDevice(d *DeviceConfig) (host, port string, err error) {
+	host, port, err = SplitHostPort(d.Host)
+	if err != nil {
+		return "", "", err
+	}
+
+	if port != "" && d.Port != nil && strconv.Itoa(*d.Port) != port {
+		return "", "", fmt.Errorf("port %s in host conflicts with port %d", port, *d.Port)
+	}
+
+	if port == "" {
+		p := c.Port
+		if d.Port != nil {
+			p = *d.Port
+		}
+		port = strconv.Itoa(p)
+	}
+
+	return host, port, nil
+}
+
+// AddressFamilyForDevice gets the preferred address family for a device
+func (c *Config) AddressFamilyForDevice(d *DeviceConfig) string {
+	if d != nil && d.AddressFamily != nil {
+		return *d.AddressFamily
+	}
+
+	return c.AddressFamily
+}
+
+func validateAddressFamily(family string) error {
+	switch family {
+	case "", AddressFamilyIPv4, AddressFamilyIPv6:
+		return nil
+	default:
+		return fmt.Errorf("invalid address_family %q, must be %s or %s", family, AddressFamilyIPv4, AddressFamilyIPv6)
+	}
+}
+
+func parsePort(port string) (int, error) {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return 0, fmt.Errorf("invalid port %q", port)
+	}
+
+	return p, nil
+}
+
+func isIPv6(host string) bool {
+	// strip the zone of link local addresses (e.g. fe80::1%eth0)
+	if i := strings.LastIndex(host, "%"); i > 0 {
+		host = host[:i]
+	}
+
+	return strings.Contains(host, ":") && net.ParseIP(host) != nil
+}
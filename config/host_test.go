@@ -0,0 +1,135 @@
+package config
+
+import (
+	"testing"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestSplitHostPort(t *testing.T) {
+	tests := []struct {
+		target string
+		host   string
+		port   string
+		err    string
+	}{
+		{target: "switch1", host: "switch1"},
+		{target: "switch1:2222", host: "switch1", port: "2222"},
+		{target: "10.0.0.1:22", host: "10.0.0.1", port: "22"},
+		{target: "2001:db8::1", host: "2001:db8::1"},
+		{target: "[2001:db8::1]", host: "2001:db8::1"},
+		{target: "[2001:db8::1]:2222", host: "2001:db8::1", port: "2222"},
+		{target: "fe80::1%eth0", host: "fe80::1%eth0"},
+		{target: "[fe80::1%eth0]:22", host: "fe80::1%eth0", port: "22"},
+		{target: "[fe80::1%eth0]", host: "fe80::1%eth0"},
+		{target: "2001:db8::1:2222", host: "2001:db8::1:2222"},
+		{target: "switch1:22:22", err: `"switch1:22:22" is not an IPv6 address, use [address]:port to add a port`},
+		{target: "[switch1]:22", err: `"switch1" is not an IPv6 address`},
+		{target: "[10.0.0.1]", err: `"10.0.0.1" is not an IPv6 address`},
+		{target: "[2001:db8::1]:", err: `missing port after "2001:db8::1"`},
+		{target: "switch1:", err: `missing port after "switch1"`},
+		{target: ":22", err: "missing host name"},
+		{target: "", err: "missing host name"},
+		{target: "switch1:0", err: `invalid port "0"`},
+		{target: "switch1:70000", err: `invalid port "70000"`},
+		{target: "switch1:ssh", err: `invalid port "ssh"`},
+		{target: "[2001:db8::1]:-1", err: `invalid port "-1"`},
+	}
+
+	for _, test := range tests {
+		host, port, err := SplitHostPort(test.target)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%q: got error %v, want %s", test.target, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.target, err)
+			continue
+		}
+		if host != test.host || port != test.port {
+			t.Errorf("%q: got %q %q, want %q %q", test.target, host, port, test.host, test.port)
+		}
+	}
+}
+
+func TestAddressForDevice(t *testing.T) {
+	c := New()
+	c.Port = 2222
+
+	tests := []struct {
+		name   string
+		device *DeviceConfig
+		host   string
+		port   string
+		err    string
+	}{
+		{name: "global port", device: &DeviceConfig{Host: "switch1"}, host: "switch1", port: "2222"},
+		{name: "device port", device: &DeviceConfig{Host: "switch1", Port: intPtr(22)}, host: "switch1", port: "22"},
+		{name: "port in host", device: &DeviceConfig{Host: "switch1:830"}, host: "switch1", port: "830"},
+		{name: "bare ipv6", device: &DeviceConfig{Host: "2001:db8::1", Port: intPtr(22)}, host: "2001:db8::1", port: "22"},
+		{name: "bracketed ipv6 with port", device: &DeviceConfig{Host: "[2001:db8::1]:830"}, host: "2001:db8::1", port: "830"},
+		{name: "zone id", device: &DeviceConfig{Host: "fe80::1%eth0"}, host: "fe80::1%eth0", port: "2222"},
+		{name: "same port in host and port", device: &DeviceConfig{Host: "[2001:db8::1]:22", Port: intPtr(22)}, host: "2001:db8::1", port: "22"},
+		{name: "conflicting ports", device: &DeviceConfig{Host: "switch1:830", Port: intPtr(22)}, err: "port 830 in host conflicts with port 22"},
+		{name: "conflicting ports ipv6", device: &DeviceConfig{Host: "[2001:db8::1]:830", Port: intPtr(22)}, err: "port 830 in host conflicts with port 22"},
+		{name: "bad port", device: &DeviceConfig{Host: "switch1:99999"}, err: `invalid port "99999"`},
+	}
+
+	for _, test := range tests {
+		host, port, err := c.AddressForDevice(test.device)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if host != test.host || port != test.port {
+			t.Errorf("%s: got %q %q, want %q %q", test.name, host, port, test.host, test.port)
+		}
+	}
+}
+
+func TestAddressFamilyForDevice(t *testing.T) {
+	tests := []struct {
+		name   string
+		global string
+		device *DeviceConfig
+		want   string
+	}{
+		{name: "no preference", device: &DeviceConfig{}, want: ""},
+		{name: "global", global: AddressFamilyIPv6, device: &DeviceConfig{}, want: AddressFamilyIPv6},
+		{name: "device overrides global", global: AddressFamilyIPv6, device: &DeviceConfig{AddressFamily: stringPtr(AddressFamilyIPv4)}, want: AddressFamilyIPv4},
+		{name: "nil device", global: AddressFamilyIPv4, want: AddressFamilyIPv4},
+	}
+
+	for _, test := range tests {
+		c := New()
+		c.AddressFamily = test.global
+		if got := c.AddressFamilyForDevice(test.device); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestValidateAddressFamily(t *testing.T) {
+	for _, family := range []string{"", AddressFamilyIPv4, AddressFamilyIPv6} {
+		if err := validateAddressFamily(family); err != nil {
+			t.Errorf("%q: unexpected error %v", family, err)
+		}
+	}
+	if err := validateAddressFamily("inet6"); err == nil {
+		t.Error("expected an error for an unknown address family")
+	}
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// passwordCommandTimeout limits how long a password_command may run
+var passwordCommandTimeout = 10 * time.Second
+
+var envVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Secret is a credential which is never printed in logs
+type Secret string
+
+// String redacts the secret so it can be safely logged
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return "<secret>"
+}
+
+// GoString redacts the secret when printed with %#v
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalYAML redacts the secret when the config is marshaled
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// ResolveSecrets expands ${ENV_VAR} references and loads passwords from files and commands
+func (c *Config) ResolveSecrets() error {
+	var err error
+
+	c.Username, err = expandEnv(c.Username)
+	if err != nil {
+		return errors.Wrap(err, "username")
+	}
+
+	c.KeyFile, err = expandEnv(c.KeyFile)
+	if err != nil {
+		return errors.Wrap(err, "key_file")
+	}
+
+	password, err := resolvePassword(c.Password, c.PasswordFile, c.PasswordCommand)
+	if err != nil {
+		return err
+	}
+	c.Password = password
+
+	for _, d := range c.Devices {
+		err = d.resolveSecrets()
+		if err != nil {
+			return errors.Wrapf(err, "device %s", d.Host)
+		}
+	}
+
+	return nil
+}
+
+func (d *DeviceConfig) resolveSecrets() error {
+	if d.Username != nil {
+		username, err := expandEnv(*d.Username)
+		if err != nil {
+			return errors.Wrap(err, "username")
+		}
+		d.Username = &username
+	}
+
+	if d.KeyFile != nil {
+		keyFile, err := expandEnv(*d.KeyFile)
+		if err != nil {
+			return errors.Wrap(err, "key_file")
+		}
+		d.KeyFile = &keyFile
+	}
+
+	if d.Password == nil && d.PasswordFile == nil && d.PasswordCommand == nil {
+		return nil
+	}
+
+	var (
+		password        Secret
+		passwordFile    string
+		passwordCommand string
+	)
+	if d.Password != nil {
+		password = *d.Password
+	}
+	if d.PasswordFile != nil {
+		passwordFile = *d.PasswordFile
+	}
+	if d.PasswordCommand != nil {
+		passwordCommand = *d.PasswordCommand
+	}
+
+	p, err := resolvePassword(password, passwordFile, passwordCommand)
+	if err != nil {
+		return err
+	}
+	d.Password = &p
+
+	return nil
+}
+
+func resolvePassword(password Secret, file, command string) (Secret, error) {
+	set := 0
+	for _, s := range []string{string(password), file, command} {
+		if s != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", errors.New("only one of password, password_file and password_command may be set")
+	}
+
+	switch {
+	case file != "":
+		return readPasswordFile(file)
+	case command != "":
+		return runPasswordCommand(command)
+	default:
+		s, err := expandEnv(string(password))
+		if err != nil {
+			return "", errors.Wrap(err, "password")
+		}
+		return Secret(s), nil
+	}
+}
+
+func readPasswordFile(file string) (Secret, error) {
+	path, err := expandEnv(file)
+	if err != nil {
+		return "", errors.Wrap(err, "password_file")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "could not read password_file")
+	}
+
+	return Secret(strings.TrimRight(string(b), "\r\n")), nil
+}
+
+func runPasswordCommand(command string) (Secret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", errors.Wrap(err, "password_command failed")
+	}
+
+	// the command itself is not part of the errors as it may contain secrets
+	err = cmd.Start()
+	if err != nil {
+		return "", errors.Wrap(err, "password_command failed")
+	}
+
+	// the output is read separately, as children of the shell can keep it open after the shell was killed
+	output := make(chan []byte, 1)
+	go func() {
+		b, _ := ioutil.ReadAll(stdout)
+		output <- b
+	}()
+
+	var b []byte
+	select {
+	case b = <-output:
+	case <-ctx.Done():
+	}
+
+	err = cmd.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "password_command failed")
+	}
+
+	return Secret(strings.TrimRight(string(b), "\r\n")), nil
+}
+
+func expandEnv(s string) (string, error) {
+	var missing []string
+	expanded := envVarRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		name := envVarRegexp.FindStringSubmatch(m)[1]
+		v, found := os.LookupEnv(name)
+		if !found {
+			missing = append(missing, name)
+		}
+		return v
+	})
+
+	if len(missing) > 0 {
+		return "", errors.Errorf("environment variable(s) not set: %s", strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
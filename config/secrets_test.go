@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("ARUBA_TEST_USER", "monitor")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "${ARUBA_TEST_USER}", want: "monitor"},
+		{in: "pre-${ARUBA_TEST_USER}-post", want: "pre-monitor-post"},
+		{in: "$ARUBA_TEST_USER", want: "$ARUBA_TEST_USER"},
+		{in: "${ARUBA_TEST_MISSING}", wantErr: "environment variable(s) not set: ARUBA_TEST_MISSING"},
+		{in: "${ARUBA_TEST_MISSING}${ARUBA_TEST_OTHER}", wantErr: "environment variable(s) not set: ARUBA_TEST_MISSING, ARUBA_TEST_OTHER"},
+	}
+
+	for _, test := range tests {
+		got, err := expandEnv(test.in)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("%s: got error %v, want %q", test.in, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestResolvePassword(t *testing.T) {
+	t.Setenv("ARUBA_TEST_PASSWORD", "from-env")
+
+	file := filepath.Join(t.TempDir(), "password")
+	err := ioutil.WriteFile(file, []byte("from-file\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password Secret
+		file     string
+		command  string
+		want     Secret
+		wantErr  string
+	}{
+		{name: "plain", password: "secret", want: "secret"},
+		{name: "env", password: "${ARUBA_TEST_PASSWORD}", want: "from-env"},
+		{name: "missing env", password: "${ARUBA_TEST_MISSING}", wantErr: "password: environment variable(s) not set: ARUBA_TEST_MISSING"},
+		{name: "file", file: file, want: "from-file"},
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing"), wantErr: "could not read password_file"},
+		{name: "command", command: "printf 'from-command\\n'", want: "from-command"},
+		{name: "failing command", command: "echo secret; exit 3", wantErr: "password_command failed: exit status 3"},
+		{name: "two sources", password: "secret", command: "echo secret", wantErr: "only one of password, password_file and password_command may be set"},
+	}
+
+	for _, test := range tests {
+		got, err := resolvePassword(test.password, test.file, test.command)
+		if test.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			if got != "" {
+				t.Errorf("%s: got password %q with an error", test.name, string(got))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, string(got), string(test.want))
+		}
+	}
+}
+
+func TestPasswordCommandTimeout(t *testing.T) {
+	timeout := passwordCommandTimeout
+	passwordCommandTimeout = 100 * time.Millisecond
+	defer func() { passwordCommandTimeout = timeout }()
+
+	// the child keeps stdout open after the shell was killed
+	start := time.Now()
+	_, err := runPasswordCommand("sleep 5 2>/dev/null")
+	if err == nil || !strings.HasPrefix(err.Error(), "password_command failed") {
+		t.Errorf("got error %v, want password_command failed", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("command was not stopped after the timeout, took %v", time.Since(start))
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	s := Secret("hunter2")
+
+	for _, format := range []string{"%s", "%v", "%+v", "%#v"} {
+		if got := fmt.Sprintf(format, s); strings.Contains(got, "hunter2") {
+			t.Errorf("%s: secret printed as %q", format, got)
+		}
+	}
+	if got := fmt.Sprintf("%v", &DeviceConfig{Host: "switch1", Password: &s}); strings.Contains(got, "hunter2") {
+		t.Errorf("secret printed in device config %q", got)
+	}
+	if got := s.String(); got != "<secret>" {
+		t.Errorf("got %q, want <secret>", got)
+	}
+	if got := Secret("").String(); got != "" {
+		t.Errorf("empty secret printed as %q", got)
+	}
+}
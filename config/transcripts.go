@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TranscriptsConfig configures where the transcripts of the sessions with devices are recorded
+type TranscriptsConfig struct {
+	Directory string   `yaml:"directory"`
+	Keep      int      `yaml:"keep,omitempty"`
+	Redact    []string `yaml:"redact,omitempty"`
+}
+
+// RecordTranscriptsForDevice checks if the transcripts of a device are recorded.
+// A setting for the device takes precedence over the global setting.
+func (c *Config) RecordTranscriptsForDevice(d *DeviceConfig) bool {
+	if d != nil && d.RecordTranscripts != nil {
+		return *d.RecordTranscripts
+	}
+
+	return c.RecordTranscripts
+}
+
+func (c *Config) validateTranscripts(root *yaml.Node) []Problem {
+	problems := []Problem{}
+	doc := documentNode(root)
+
+	if c.Transcripts != nil {
+		n := mappingValue(doc, "transcripts")
+		if c.Transcripts.Directory == "" {
+			problems = append(problems, Problem{Line: nodeLine(n), Message: "transcripts: missing directory"})
+		}
+		if c.Transcripts.Keep < 0 {
+			problems = append(problems, Problem{Line: nodeLine(mappingValue(n, "keep")), Message: fmt.Sprintf("transcripts: invalid keep %d", c.Transcripts.Keep)})
+		}
+		items := sequenceItems(mappingValue(n, "redact"))
+		for i, re := range c.Transcripts.Redact {
+			_, err := regexp.Compile(re)
+			if err != nil {
+				var line int
+				if i < len(items) {
+					line = items[i].Line
+				}
+				problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("transcripts: invalid redact regex: %v", err)})
+			}
+		}
+		return problems
+	}
+
+	if c.RecordTranscripts {
+		problems = append(problems, Problem{Line: nodeLine(mappingValue(doc, "record_transcripts")), Message: "record_transcripts requires a transcripts directory"})
+	}
+	deviceNodes := sequenceItems(mappingValue(doc, "devices"))
+	for i, d := range c.Devices {
+		if d.RecordTranscripts == nil || !*d.RecordTranscripts {
+			continue
+		}
+		var n *yaml.Node
+		if i < len(deviceNodes) {
+			n = deviceNodes[i]
+		}
+		problems = append(problems, Problem{Line: nodeLine(mappingValue(n, "record_transcripts")), Message: "record_transcripts requires a transcripts directory"})
+	}
+
+	return problems
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	lineRegexp         = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldRegexp = regexp.MustCompile(`^field (\S+) not found in type config\.\w+$`)
+	labelNameRegexp    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Problem is a single issue found in a config file
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// ValidationError contains all problems found in a config file
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	s := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		s[i] = p.String()
+	}
+
+	return "invalid config: " + strings.Join(s, "; ")
+}
+
+// Check parses a config from reader and returns all problems found in it.
+// Secrets are not resolved, so password commands are never run.
+func Check(reader io.Reader) ([]Problem, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	_, problems := parse(b)
+
+	return problems, nil
+}
+
+func parse(b []byte) (*Config, []Problem) {
+	c := New()
+	problems := []Problem{}
+
+	// unknown fields are reported as type errors after all other fields
+	// have been decoded, so the remaining checks can still run
+	err := decodeStrict(b, c)
+	if err != nil {
+		problems = append(problems, problemsFromError(err)...)
+		if _, ok := err.(*yaml.TypeError); !ok {
+			return c, problems
+		}
+	}
+
+	var root yaml.Node
+	err = yaml.Unmarshal(b, &root)
+	if err != nil {
+		return c, append(problems, problemsFromError(err)...)
+	}
+
+	// templates are parsed during validation, so their paths have to be resolved first
+	for _, cc := range c.CustomCollectors {
+		if cc.Template != "" {
+			cc.Template = c.TemplatePath(cc.Template)
+		}
+	}
+
+	problems = append(problems, c.validate(&root)...)
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+
+	return c, problems
+}
+
+func decodeStrict(b []byte, c *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	err := dec.Decode(c)
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
+
+func problemsFromError(err error) []Problem {
+	var msgs []string
+	if terr, ok := err.(*yaml.TypeError); ok {
+		msgs = terr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+
+	problems := make([]Problem, len(msgs))
+	for i, msg := range msgs {
+		problems[i] = Problem{Message: msg}
+		if matches := lineRegexp.FindStringSubmatch(msg); matches != nil {
+			problems[i].Line, _ = strconv.Atoi(matches[1])
+			problems[i].Message = matches[2]
+		}
+		if matches := unknownFieldRegexp.FindStringSubmatch(problems[i].Message); matches != nil {
+			problems[i].Message = fmt.Sprintf("unknown field %q", matches[1])
+		}
+	}
+
+	return problems
+}
+
+func (c *Config) validate(root *yaml.Node) []Problem {
+	problems := []Problem{}
+
+	deviceNodes := sequenceItems(mappingValue(documentNode(root), "devices"))
+
+	problems = append(problems, checkLabelNames(mappingValue(documentNode(root), "labels"))...)
+	groupsNode := mappingValue(documentNode(root), "groups")
+	for name := range c.Groups {
+		problems = append(problems, checkLabelNames(mappingValue(mappingValue(groupsNode, name), "labels"))...)
+	}
+
+	if c.KeyFile != "" {
+		line := nodeLine(mappingValue(documentNode(root), "key_file"))
+		problems = append(problems, checkKeyFile(c.KeyFile, line)...)
+	}
+
+	if c.Inventory != nil {
+		dirsNode := mappingValue(mappingValue(documentNode(root), "inventory"), "directories")
+		for i, dir := range c.Inventory.Directories {
+			var line int
+			if items := sequenceItems(dirsNode); i < len(items) {
+				line = items[i].Line
+			}
+			fi, err := os.Stat(dir)
+			if err != nil {
+				problems = append(problems, Problem{Line: line, Message: "inventory directory is not readable: " + err.Error()})
+			} else if !fi.IsDir() {
+				problems = append(problems, Problem{Line: line, Message: fmt.Sprintf("inventory directory %s is not a directory", dir)})
+			}
+		}
+	}
+
+	_, err := parsePort(strconv.Itoa(c.Port))
+	if err != nil {
+		problems = append(problems, Problem{Line: nodeLine(mappingValue(documentNode(root), "port")), Message: err.Error()})
+	}
+
+	err = validateAddressFamily(c.AddressFamily)
+	if err != nil {
+		problems = append(problems, Problem{Line: nodeLine(mappingValue(documentNode(root), "address_family")), Message: err.Error()})
+	}
+
+	hosts := make(map[string]int)
+	for i, d := range c.Devices {
+		var n *yaml.Node
+		if i < len(deviceNodes) {
+			n = deviceNodes[i]
+		}
+		hostLine := nodeLine(mappingValue(n, "host"))
+		if hostLine == 0 {
+			hostLine = nodeLine(n)
+		}
+
+		if d.Host == "" {
+			problems = append(problems, Problem{Line: hostLine, Message: fmt.Sprintf("device %d has no host", i+1)})
+		} else {
+			if first, found := hosts[d.Host]; found {
+				problems = append(problems, Problem{Line: hostLine, Message: fmt.Sprintf("duplicate host %q, first defined at line %d", d.Host, first)})
+			} else {
+				hosts[d.Host] = hostLine
+			}
+
+			_, _, err := c.AddressForDevice(d)
+			if err != nil {
+				problems = append(problems, Problem{Line: hostLine, Message: fmt.Sprintf("invalid host %q: %v", d.Host, err)})
+			}
+		}
+
+		if d.Port != nil {
+			_, err := parsePort(strconv.Itoa(*d.Port))
+			if err != nil {
+				problems = append(problems, Problem{Line: nodeLine(mappingValue(n, "port")), Message: err.Error()})
+			}
+		}
+
+		if d.AddressFamily != nil {
+			err := validateAddressFamily(*d.AddressFamily)
+			if err != nil {
+				problems = append(problems, Problem{Line: nodeLine(mappingValue(n, "address_family")), Message: err.Error()})
+			}
+		}
+
+		if d.KeyFile != nil {
+			problems = append(problems, checkKeyFile(*d.KeyFile, nodeLine(mappingValue(n, "key_file")))...)
+		}
+
+		if _, found := c.Groups[d.Group]; d.Group != "" && !found {
+			problems = append(problems, Problem{Line: nodeLine(mappingValue(n, "group")), Message: fmt.Sprintf("unknown group %q", d.Group)})
+		}
+
+		problems = append(problems, checkLabelNames(mappingValue(n, "labels"))...)
+
+		if d.OSType != "" && !isKnownOSType(d.OSType) {
+			problems = append(problems, Problem{Line: nodeLine(mappingValue(n, "os_type")), Message: fmt.Sprintf("unknown os_type %q, must be one of %s", d.OSType, strings.Join(osTypes, ", "))})
+		}
+	}
+
+	problems = append(problems, c.validateCustomCollectors(root)...)
+	problems = append(problems, c.validateTranscripts(root)...)
+
+	return problems
+}
+
+// IsValidLabelName checks if name can be used as a custom label name
+func IsValidLabelName(name string) bool {
+	return labelNameRegexp.MatchString(name) && !strings.HasPrefix(name, "__")
+}
+
+func checkLabelNames(labels *yaml.Node) []Problem {
+	if labels == nil || labels.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	problems := []Problem{}
+	for i := 0; i < len(labels.Content); i += 2 {
+		k := labels.Content[i]
+		if !IsValidLabelName(k.Value) {
+			problems = append(problems, Problem{Line: k.Line, Message: fmt.Sprintf("invalid label name %q", k.Value)})
+			continue
+		}
+		if k.Value == "target" {
+			problems = append(problems, Problem{Line: k.Line, Message: "label name \"target\" is reserved"})
+		}
+	}
+
+	return problems
+}
+
+func checkKeyFile(keyFile string, line int) []Problem {
+	path, err := expandEnv(keyFile)
+	if err != nil {
+		return []Problem{{Line: line, Message: "key_file: " + err.Error()}}
+	}
+
+	_, err = ioutil.ReadFile(path)
+	if err != nil {
+		return []Problem{{Line: line, Message: "key_file is not readable: " + err.Error()}}
+	}
+
+	return nil
+}
+
+func documentNode(n *yaml.Node) *yaml.Node {
+	if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return n.Content[0]
+	}
+
+	return n
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func sequenceItems(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return n.Content
+}
+
+func nodeLine(n *yaml.Node) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.Line
+}
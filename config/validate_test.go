@@ -0,0 +1,150 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	RegisterFeature("system", true)
+
+	tests := []struct {
+		name string
+		yaml string
+		want []Problem
+	}{
+		{
+			name: "valid",
+			yaml: "timeout: 5\nusername: admin\nlabels:\n  site: hq\n",
+			want: []Problem{},
+		},
+		{
+			name: "unknown key",
+			yaml: "timeout: 5\nusername: admin\nverbose: true\n",
+			want: []Problem{{Line: 3, Message: `unknown field "verbose"`}},
+		},
+		{
+			name: "unknown device key",
+			yaml: "devices:\n  - host: switch1\n    timout: 3\n",
+			want: []Problem{{Line: 3, Message: `unknown field "timout"`}},
+		},
+		{
+			name: "wrong type",
+			yaml: "timeout: 5\nport: twenty-two\n",
+			want: []Problem{{Line: 2, Message: "cannot unmarshal !!str `twenty-two` into int"}},
+		},
+		{
+			name: "invalid label name",
+			yaml: "labels:\n  site: hq\n  bad-name: x\n",
+			want: []Problem{{Line: 3, Message: `invalid label name "bad-name"`}},
+		},
+		{
+			name: "invalid group label name",
+			yaml: "groups:\n  core:\n    labels:\n      1st: x\n",
+			want: []Problem{{Line: 4, Message: `invalid label name "1st"`}},
+		},
+		{
+			name: "invalid device label name",
+			yaml: "devices:\n  - host: switch1\n    labels:\n      bad-name: x\n",
+			want: []Problem{{Line: 4, Message: `invalid label name "bad-name"`}},
+		},
+		{
+			name: "duplicate hosts",
+			yaml: "devices:\n  - host: switch1\n  - host: switch2\n  - host: switch1\n",
+			want: []Problem{{Line: 4, Message: `duplicate host "switch1", first defined at line 2`}},
+		},
+		{
+			name: "device without host",
+			yaml: "devices:\n  - port: 22\n",
+			want: []Problem{{Line: 2, Message: "device 1 has no host"}},
+		},
+		{
+			name: "unreadable key_file",
+			yaml: "key_file: /nonexistent/id_rsa\n",
+			want: []Problem{{Line: 1, Message: "key_file is not readable: open /nonexistent/id_rsa: no such file or directory"}},
+		},
+		{
+			name: "unreadable device key_file",
+			yaml: "devices:\n  - host: switch1\n    key_file: /nonexistent/id_rsa\n",
+			want: []Problem{{Line: 3, Message: "key_file is not readable: open /nonexistent/id_rsa: no such file or directory"}},
+		},
+		{
+			name: "unknown feature",
+			yaml: "features:\n  system: true\n  bgpp: false\n",
+			want: []Problem{{Line: 3, Message: `unknown feature "bgpp"`}},
+		},
+		{
+			name: "unknown device feature",
+			yaml: "devices:\n  - host: switch1\n    features:\n      sytem: false\n",
+			want: []Problem{{Line: 4, Message: `unknown feature "sytem"`}},
+		},
+		{
+			name: "invalid port in host",
+			yaml: "devices:\n  - host: switch1:99999\n",
+			want: []Problem{{Line: 2, Message: `invalid host "switch1:99999": invalid port "99999"`}},
+		},
+		{
+			name: "port in host conflicts with port",
+			yaml: "devices:\n  - host: switch1:830\n    port: 22\n",
+			want: []Problem{{Line: 2, Message: `invalid host "switch1:830": port 830 in host conflicts with port 22`}},
+		},
+		{
+			name: "ambiguous ipv6 host with port",
+			yaml: "devices:\n  - host: \"switch1:22:22\"\n",
+			want: []Problem{{Line: 2, Message: `invalid host "switch1:22:22": "switch1:22:22" is not an IPv6 address, use [address]:port to add a port`}},
+		},
+		{
+			name: "all problems sorted by line",
+			yaml: "labels:\n  bad-name: x\ntimeout: 5\nport: twenty-two\nverbose: true\n",
+			want: []Problem{
+				{Line: 2, Message: `invalid label name "bad-name"`},
+				{Line: 4, Message: "cannot unmarshal !!str `twenty-two` into int"},
+				{Line: 5, Message: `unknown field "verbose"`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Check(strings.NewReader(test.yaml))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	_, err := Load(strings.NewReader("timeout: 5\nverbose: true\n"))
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("got error %v, want a validation error", err)
+	}
+	if len(verr.Problems) != 1 || verr.Problems[0].Line != 2 {
+		t.Errorf("got problems %v", verr.Problems)
+	}
+	if want := `invalid config: line 2: unknown field "verbose"`; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestCheckReadableKeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "id_rsa")
+	err := ioutil.WriteFile(keyFile, []byte("key"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Check(strings.NewReader("key_file: " + keyFile + "\ndevices:\n  - host: switch1\n    key_file: " + keyFile + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got problems %v", got)
+	}
+}
@@ -2,14 +2,17 @@ package connector
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/transcript"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
@@ -19,13 +22,20 @@ import (
 
 // SSHConnection encapsulates the connection to the device
 type SSHConnection struct {
-	client       *ssh.Client
-	Host         string
-	stdin        io.WriteCloser
-	stdout       io.Reader
-	session      *ssh.Session
-	batchSize    int
-	clientConfig *ssh.ClientConfig
+	client        *ssh.Client
+	Host          string
+	host          string
+	port          string
+	addressFamily string
+	stdin         io.WriteCloser
+	stdout        io.Reader
+	session       *ssh.Session
+	batchSize     int
+	clientConfig  *ssh.ClientConfig
+	// CommandDelay is the time waited after sending each command before the output is read
+	CommandDelay time.Duration
+	// Transcript records the commands and outputs, nil if recording is disabled for the device
+	Transcript *transcript.Recorder
 }
 
 type result struct {
@@ -52,6 +62,11 @@ func NewSSSHConnection(device *Device, cfg *config.Config) (*SSHConnection, erro
 		timeout = *deviceConfig.Timeout
 	}
 
+	commandDelay := cfg.CommandDelay
+	if deviceConfig.CommandDelay != nil {
+		commandDelay = *deviceConfig.CommandDelay
+	}
+
 	sshConfig := &ssh.ClientConfig{
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 		Timeout:         time.Duration(timeout) * time.Second,
@@ -64,9 +79,21 @@ func NewSSSHConnection(device *Device, cfg *config.Config) (*SSHConnection, erro
 	device.Auth(sshConfig)
 
 	c := &SSHConnection{
-		Host:         device.Host + ":" + device.Port,
-		batchSize:    batchSize,
-		clientConfig: sshConfig,
+		Host:          net.JoinHostPort(device.Host, device.Port),
+		host:          device.Host,
+		port:          device.Port,
+		addressFamily: device.AddressFamily,
+		batchSize:     batchSize,
+		clientConfig:  sshConfig,
+		CommandDelay:  time.Duration(commandDelay) * time.Millisecond,
+	}
+
+	if cfg.RecordTranscriptsForDevice(deviceConfig) && cfg.Transcripts != nil {
+		redact, err := transcript.RegexRedactor(cfg.Transcripts.Redact)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid redact regex")
+		}
+		c.Transcript = transcript.New(cfg.Transcripts.Directory, deviceConfig.Host, cfg.Transcripts.Keep, redact)
 	}
 
 	err := c.Connect()
@@ -80,10 +107,20 @@ func NewSSSHConnection(device *Device, cfg *config.Config) (*SSHConnection, erro
 // Connect connects to the device
 func (c *SSHConnection) Connect() error {
 	var (
-		err error
+		err    error
 		output string
 	)
-	c.client, err = ssh.Dial("tcp", c.Host, c.clientConfig)
+	addrs, err := c.resolve()
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		c.client, err = ssh.Dial("tcp", net.JoinHostPort(addr, c.port), c.clientConfig)
+		if err == nil {
+			break
+		}
+		log.Debugf("Could not connect to %s via %s: %v\n", c.Host, addr, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -96,9 +133,9 @@ func (c *SSHConnection) Connect() error {
 	c.stdin, _ = session.StdinPipe()
 	c.stdout, _ = session.StdoutPipe()
 	modes := ssh.TerminalModes{
-		ssh.ECHO: 1,
+		ssh.ECHO:    1,
 		ssh.ECHOCTL: 0,
-		ssh.OCRNL: 0,
+		ssh.OCRNL:   0,
 	}
 	session.RequestPty("vt100", 0, 2000, modes)
 	session.Shell()
@@ -115,13 +152,40 @@ func (c *SSHConnection) Connect() error {
 	return nil
 }
 
+// resolve gets the addresses of the host with the preferred address family first
+func (c *SSHConnection) resolve() ([]string, error) {
+	if net.ParseIP(c.host) != nil || strings.Contains(c.host, "%") {
+		return []string{c.host}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.clientConfig.Timeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, c.host)
+	if err != nil {
+		return nil, err
+	}
+
+	preferred := []string{}
+	other := []string{}
+	for _, ip := range ips {
+		isIPv4 := ip.IP.To4() != nil
+		if (c.addressFamily == config.AddressFamilyIPv4 && !isIPv4) || (c.addressFamily == config.AddressFamilyIPv6 && isIPv4) {
+			other = append(other, ip.String())
+			continue
+		}
+		preferred = append(preferred, ip.String())
+	}
+
+	return append(preferred, other...), nil
+}
+
 // RunCommand runs a command or commands against the device
 func (c *SSHConnection) RunCommand(cmds []string) (string, error) {
 	buf := bufio.NewReader(c.stdout)
 	for i := 0; i < len(cmds); i++ {
 		log.Debugf("Running command on %s: %s\n", c.Host, cmds[i])
 		io.WriteString(c.stdin, fmt.Sprintf("%s", cmds[i])+"\n")
-		time.Sleep(1 * time.Second)
+		time.Sleep(c.CommandDelay)
 	}
 
 	outputChan := make(chan result)
@@ -130,12 +194,20 @@ func (c *SSHConnection) RunCommand(cmds []string) (string, error) {
 	}()
 	select {
 	case res := <-outputChan:
+		if res.err == nil {
+			c.Transcript.Record(cmds, res.output)
+		}
 		return res.output, res.err
 	case <-time.After(c.clientConfig.Timeout):
 		return "", errors.New("Timeout reached")
 	}
 }
 
+// SetTimeout changes how long RunCommand waits for the output of the commands
+func (c *SSHConnection) SetTimeout(timeout time.Duration) {
+	c.clientConfig.Timeout = timeout
+}
+
 // BlindSend sends commands to a device and doesn't wait for output
 func (c *SSHConnection) BlindSend(cmds []string) {
 	for i := 0; i < len(cmds); i++ {
@@ -179,6 +251,7 @@ func (c *SSHConnection) readln(ch chan result, cmd string, r io.Reader) {
 		n, err := r.Read(buf)
 		if err != nil {
 			ch <- result{output: "", err: err}
+			return
 		}
 		cleanStr := escSequence.ReplaceAllString(string(buf[:n]), "")
 		loadStr += cleanStr
@@ -187,11 +260,10 @@ func (c *SSHConnection) readln(ch chan result, cmd string, r io.Reader) {
 			log.Debugf("command match: %v", cmd)
 			if matches := endPrompt.FindStringSubmatch(loadStr); matches != nil {
 				log.Debugf("prompt match: %v", matches[0])
-			    break
+				break
 			}
 		}
 	}
 	loadStr = strings.Replace(loadStr, "\r", "", -1)
 	ch <- result{output: loadStr, err: nil}
 }
-
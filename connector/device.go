@@ -8,12 +8,15 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
+// Device is a device to connect to. Host is the address or name to connect to,
+// the name configured by the user is kept in DeviceConfig.Host.
 type Device struct {
-	Host         string
-	Port         string
-	Auth         AuthMethod
-	ClientConfig ssh.ClientConfig
-	DeviceConfig *config.DeviceConfig
+	Host          string
+	Port          string
+	AddressFamily string
+	Auth          AuthMethod
+	ClientConfig  ssh.ClientConfig
+	DeviceConfig  *config.DeviceConfig
 }
 
 // AuthMethod is the method to use to authenticate agaist the device
@@ -40,5 +43,5 @@ func AuthByKey(username string, key io.Reader) (AuthMethod, error) {
 }
 
 func (d *Device) String() string {
-	return d.Host
+	return d.DeviceConfig.Host
 }
@@ -0,0 +1,122 @@
+package custom
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/rpc"
+	"github.com/slashdoom/aruba_exporter/textfsm"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+type customMetric struct {
+	cfg       *config.CustomMetricConfig
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}
+
+type customCollector struct {
+	cfg      *config.CustomCollectorConfig
+	template *textfsm.Template
+	err      error
+	metrics  []*customMetric
+}
+
+// NewCollector creates a new collector for a custom collector definition. The template
+// of the definition is parsed once when the config is loaded and shared by all scrapes.
+func NewCollector(cfg *config.CustomCollectorConfig) collector.RPCCollector {
+	c := &customCollector{cfg: cfg, template: cfg.TextFSM()}
+
+	if cfg.Template != "" && c.template == nil {
+		c.err = errors.Errorf("custom collector %s: template %s was not loaded", cfg.Name, cfg.Template)
+	}
+
+	for _, m := range cfg.Metrics {
+		l := append([]string{"target"}, m.Labels...)
+		c.metrics = append(c.metrics, &customMetric{
+			cfg:       m,
+			desc:      collector.NewDesc(m.Name, m.Help, l),
+			valueType: valueType(m.Type),
+		})
+	}
+
+	return c
+}
+
+func valueType(t string) prometheus.ValueType {
+	switch t {
+	case "counter":
+		return prometheus.CounterValue
+	case "untyped":
+		return prometheus.UntypedValue
+	default:
+		return prometheus.GaugeValue
+	}
+}
+
+// Name returns the name of the collector
+func (c *customCollector) Name() string {
+	return c.cfg.Name
+}
+
+// Describe describes the metrics
+func (c *customCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+}
+
+// Collect collects metrics from Aruba Devices
+func (c *customCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	cmd, found := c.cfg.Commands[client.OSType]
+	if !found {
+		log.Debugf("custom collector %s has no command for %s", c.cfg.Name, client.OSType)
+		return nil
+	}
+
+	out, err := client.RunCommand([]string{cmd})
+	if err != nil {
+		return err
+	}
+
+	records, err := c.Parse(out)
+	if err != nil {
+		return errors.Wrapf(err, "custom collector %s", c.cfg.Name)
+	}
+
+	for _, m := range c.metrics {
+		seen := make(map[string]bool)
+		for _, r := range records {
+			value, ok := m.value(r)
+			if !ok {
+				log.Debugf("custom collector %s: could not convert %q for %s", c.cfg.Name, r[m.cfg.Value], m.cfg.Name)
+				continue
+			}
+
+			l := append([]string{}, labelValues...)
+			for _, name := range m.cfg.Labels {
+				l = append(l, r[name])
+			}
+
+			key := ""
+			for _, v := range l {
+				key += v + "\xff"
+			}
+			if seen[key] {
+				log.Debugf("custom collector %s: skipping duplicate %s for %v", c.cfg.Name, m.cfg.Name, l)
+				continue
+			}
+			seen[key] = true
+
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, value, l...)
+		}
+	}
+
+	return nil
+}
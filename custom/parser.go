@@ -0,0 +1,140 @@
+package custom
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Record holds the columns extracted from one match or table row
+type Record map[string]string
+
+// Parse extracts the records from the command output by regex, as a table or with a TextFSM template
+func (c *customCollector) Parse(output string) ([]Record, error) {
+	log.Debugf("output: %s\n", output)
+
+	if c.cfg.Table != nil {
+		return c.parseTable(output)
+	}
+
+	if c.template != nil {
+		return c.parseTemplate(output)
+	}
+
+	return c.parseRegex(output)
+}
+
+func (c *customCollector) parseRegex(output string) ([]Record, error) {
+	re, err := regexp.Compile(`(?m)` + c.cfg.Regex)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []Record{}
+	names := re.SubexpNames()
+	for _, matches := range re.FindAllStringSubmatch(strings.ReplaceAll(output, "\r", ""), -1) {
+		r := Record{}
+		for i, name := range names {
+			if name != "" {
+				r[name] = strings.TrimSpace(matches[i])
+			}
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+func (c *customCollector) parseTemplate(output string) ([]Record, error) {
+	parsed, err := c.template.ParseText(output)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(parsed))
+	for i, p := range parsed {
+		records[i] = Record(p)
+	}
+
+	return records, nil
+}
+
+// parseTable splits the lines between the start and end pattern into columns.
+// Lines with fewer fields than columns are skipped, surplus fields are added to the last column.
+func (c *customCollector) parseTable(output string) ([]Record, error) {
+	t := c.cfg.Table
+
+	var startRegexp, endRegexp *regexp.Regexp
+	var err error
+	if t.Start != "" {
+		startRegexp, err = regexp.Compile(t.Start)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t.End != "" {
+		endRegexp, err = regexp.Compile(t.End)
+		if err != nil {
+			return nil, err
+		}
+	}
+	separator := `\s+`
+	if t.Separator != "" {
+		separator = t.Separator
+	}
+	separatorRegexp, err := regexp.Compile(separator)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []Record{}
+	inTable := startRegexp == nil
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		log.Tracef("line: %s\n", line)
+
+		if !inTable {
+			inTable = startRegexp.MatchString(line)
+			continue
+		}
+		if endRegexp != nil && endRegexp.MatchString(line) {
+			break
+		}
+		if line == "" {
+			continue
+		}
+
+		fields := separatorRegexp.Split(line, len(t.Columns))
+		if len(fields) < len(t.Columns) {
+			log.Tracef("skipping line with %d fields\n", len(fields))
+			continue
+		}
+
+		r := Record{}
+		for i, name := range t.Columns {
+			r[name] = strings.TrimSpace(fields[i])
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// value converts the value column of a record, using the value map if one is configured
+func (m *customMetric) value(r Record) (float64, bool) {
+	s := r[m.cfg.Value]
+
+	if m.cfg.ValueMap != nil {
+		v, found := m.cfg.ValueMap[s]
+		return v, found
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
@@ -0,0 +1,277 @@
+package custom
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const vlanOutput = "switch1# show vlan\r\n" +
+	"\r\n" +
+	" Status and Counters - VLAN Information\r\n" +
+	"\r\n" +
+	"  VLAN ID Name                 | Status     Voice Jumbo\r\n" +
+	"  ------- -------------------- + ---------- ----- -----\r\n" +
+	"  1       DEFAULT_VLAN         | Port-based No    No   \r\n" +
+	"  20      users                | Port-based Yes   No   \r\n" +
+	"  30      \r\n" +
+	"\r\n" +
+	"switch1# "
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *config.TableConfig
+		want  []Record
+	}{
+		{
+			name:  "columns after header",
+			table: &config.TableConfig{Columns: []string{"id", "name", "sep", "status", "voice", "jumbo"}, Start: `^-+\s`},
+			want: []Record{
+				{"id": "1", "name": "DEFAULT_VLAN", "sep": "|", "status": "Port-based", "voice": "No", "jumbo": "No"},
+				{"id": "20", "name": "users", "sep": "|", "status": "Port-based", "voice": "Yes", "jumbo": "No"},
+			},
+		},
+		{
+			name:  "surplus fields in last column",
+			table: &config.TableConfig{Columns: []string{"id", "rest"}, Start: `^-+\s`, End: `#`},
+			want: []Record{
+				{"id": "1", "rest": "DEFAULT_VLAN         | Port-based No    No"},
+				{"id": "20", "rest": "users                | Port-based Yes   No"},
+			},
+		},
+		{
+			name:  "custom separator",
+			table: &config.TableConfig{Columns: []string{"vlan", "flags"}, Start: `^-+\s`, Separator: `\s*\|\s*`},
+			want: []Record{
+				{"vlan": "1       DEFAULT_VLAN", "flags": "Port-based No    No"},
+				{"vlan": "20      users", "flags": "Port-based Yes   No"},
+			},
+		},
+		{
+			name:  "end pattern",
+			table: &config.TableConfig{Columns: []string{"id", "name"}, Start: `^-+\s`, End: `^20\s`},
+			want: []Record{
+				{"id": "1", "name": "DEFAULT_VLAN         | Port-based No    No"},
+			},
+		},
+		{
+			name:  "no start pattern",
+			table: &config.TableConfig{Columns: []string{"word"}, End: `^VLAN ID`},
+			want: []Record{
+				{"word": "switch1# show vlan"},
+				{"word": "Status and Counters - VLAN Information"},
+			},
+		},
+		{
+			name:  "start not found",
+			table: &config.TableConfig{Columns: []string{"id"}, Start: `^no such header$`},
+			want:  []Record{},
+		},
+	}
+
+	for _, test := range tests {
+		c := &customCollector{cfg: &config.CustomCollectorConfig{Table: test.table}}
+		got, err := c.Parse(vlanOutput)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseTableInvalidPattern(t *testing.T) {
+	for _, table := range []*config.TableConfig{
+		{Columns: []string{"id"}, Start: "("},
+		{Columns: []string{"id"}, End: "("},
+		{Columns: []string{"id"}, Separator: "("},
+	} {
+		c := &customCollector{cfg: &config.CustomCollectorConfig{Table: table}}
+		_, err := c.Parse(vlanOutput)
+		if err == nil {
+			t.Errorf("%+v: expected an error", table)
+		}
+	}
+}
+
+func TestParseRegex(t *testing.T) {
+	tests := []struct {
+		name  string
+		regex string
+		want  []Record
+	}{
+		{
+			name:  "named groups",
+			regex: `^\s+(?P<id>\d+)\s+(?P<name>\S+)\s+\|\s+\S+\s+(?P<voice>Yes|No)`,
+			want: []Record{
+				{"id": "1", "name": "DEFAULT_VLAN", "voice": "No"},
+				{"id": "20", "name": "users", "voice": "Yes"},
+			},
+		},
+		{
+			name:  "unnamed groups are ignored",
+			regex: `^\s+(?P<id>\d+)\s+(\S+)\s+\|`,
+			want: []Record{
+				{"id": "1"},
+				{"id": "20"},
+			},
+		},
+		{
+			name:  "optional group",
+			regex: `^\s+(?P<id>\d+)\s+(?P<name>[A-Za-z_]+)?[ \t]*$`,
+			want: []Record{
+				{"id": "30", "name": ""},
+			},
+		},
+		{
+			name:  "values are trimmed and carriage returns removed",
+			regex: `(?P<jumbo>No\s*)$`,
+			want: []Record{
+				{"jumbo": "No"},
+				{"jumbo": "No"},
+			},
+		},
+		{
+			name:  "no match",
+			regex: `^(?P<id>VLAN \d+)`,
+			want:  []Record{},
+		},
+	}
+
+	for _, test := range tests {
+		c := &customCollector{cfg: &config.CustomCollectorConfig{Regex: test.regex}}
+		got, err := c.Parse(vlanOutput)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		valueMap map[string]float64
+		want     float64
+		ok       bool
+	}{
+		{value: "42", want: 42, ok: true},
+		{value: "-1.5", want: -1.5, ok: true},
+		{value: "87%", want: 87, ok: true},
+		{value: "1e3", want: 1000, ok: true},
+		{value: "", ok: false},
+		{value: "n/a", ok: false},
+		{value: "10 Mbps", ok: false},
+		{value: "1,024", ok: false},
+		{value: "up", valueMap: map[string]float64{"up": 1, "down": 0}, want: 1, ok: true},
+		{value: "down", valueMap: map[string]float64{"up": 1, "down": 0}, want: 0, ok: true},
+		{value: "42", valueMap: map[string]float64{"up": 1}, ok: false},
+	}
+
+	for _, test := range tests {
+		m := &customMetric{cfg: &config.CustomMetricConfig{Value: "v", ValueMap: test.valueMap}}
+		got, ok := m.value(Record{"v": test.value})
+		if got != test.want || ok != test.ok {
+			t.Errorf("%q: got %v %v, want %v %v", test.value, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+type fakeConnection struct {
+	output string
+}
+
+func (f *fakeConnection) RunCommand(cmds []string) (string, error) {
+	return f.output, nil
+}
+
+// collect runs a custom collector against the output and returns the samples as "labels value"
+func collect(t *testing.T, cfg *config.CustomCollectorConfig, output string) []string {
+	t.Helper()
+
+	client := rpc.NewClientForConnection(&fakeConnection{output: output}, "switch1", "")
+	client.OSType = rpc.ArubaSwitch
+
+	ch := make(chan prometheus.Metric, 100)
+	err := NewCollector(cfg).Collect(client, ch, []string{"switch1"})
+	close(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	samples := []string{}
+	for m := range ch {
+		var d dto.Metric
+		err := m.Write(&d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		labels := []string{}
+		for _, l := range d.GetLabel() {
+			labels = append(labels, l.GetName()+"="+l.GetValue())
+		}
+		samples = append(samples, strings.Join(labels, ",")+" "+strconv.FormatFloat(d.GetGauge().GetValue(), 'g', -1, 64))
+	}
+	sort.Strings(samples)
+
+	return samples
+}
+
+func TestCollectLabelValueColumns(t *testing.T) {
+	cfg := &config.CustomCollectorConfig{
+		Name:     "vlan",
+		Commands: map[string]string{rpc.ArubaSwitch: "show vlan"},
+		Regex:    `^\s+(?P<id>\d+)\s+(?P<name>\S+)\s+\|\s+\S+\s+(?P<voice>Yes|No)`,
+		Metrics: []*config.CustomMetricConfig{
+			{Name: "vlan_id", Value: "id", Labels: []string{"name"}},
+			{Name: "vlan_id_labeled", Value: "id", Labels: []string{"id"}},
+			{Name: "vlan_voice", Value: "voice", Labels: []string{"voice"}, ValueMap: map[string]float64{"Yes": 1, "No": 0}},
+			{Name: "vlan_name", Value: "name", Labels: []string{"id"}},
+			{Name: "vlan_missing_column", Value: "id", Labels: []string{"missing"}},
+		},
+	}
+
+	got := collect(t, cfg, vlanOutput)
+	want := []string{
+		"id=1,target=switch1 1",
+		"id=20,target=switch1 20",
+		"missing=,target=switch1 1",
+		"name=DEFAULT_VLAN,target=switch1 1",
+		"name=users,target=switch1 20",
+		"target=switch1,voice=No 0",
+		"target=switch1,voice=Yes 1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTemplateNotLoaded(t *testing.T) {
+	cfg := &config.CustomCollectorConfig{
+		Name:     "trunks",
+		Commands: map[string]string{rpc.ArubaSwitch: "show trunks"},
+		Template: "trunks.textfsm",
+	}
+
+	client := rpc.NewClientForConnection(&fakeConnection{}, "switch1", "")
+	client.OSType = rpc.ArubaSwitch
+	err := NewCollector(cfg).Collect(client, make(chan prometheus.Metric, 1), []string{"switch1"})
+	if err == nil || err.Error() != "custom collector trunks: template trunks.textfsm was not loaded" {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -2,14 +2,19 @@ package main
 
 import (
 	"os"
-	"strings"
+	"sort"
 
+	"github.com/slashdoom/aruba_exporter/collector"
 	"github.com/slashdoom/aruba_exporter/config"
 	"github.com/slashdoom/aruba_exporter/connector"
+	"github.com/slashdoom/aruba_exporter/rpc"
 
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
+const configSource = "config"
+
 func devicesForConfig(cfg *config.Config) ([]*connector.Device, error) {
 	devs := make([]*connector.Device, len(cfg.Devices))
 	var err error
@@ -23,25 +28,91 @@ func devicesForConfig(cfg *config.Config) ([]*connector.Device, error) {
 	return devs, nil
 }
 
+// devicesForInventory merges the devices from the config with the discovered devices.
+// Discovered devices which are already defined in the config or which are invalid are skipped.
+func devicesForInventory(cfg *config.Config, static []*connector.Device, discovered map[string][]*config.DeviceConfig) ([]*connector.Device, map[string]int) {
+	devs := append([]*connector.Device{}, static...)
+	counts := map[string]int{configSource: len(static)}
+
+	hosts := make(map[string]string)
+	for _, d := range static {
+		hosts[d.DeviceConfig.Host] = configSource
+	}
+
+	sources := make([]string, 0, len(discovered))
+	for source := range discovered {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	reserved := collector.LabelNames()
+	for _, source := range sources {
+		counts[source] = 0
+		for _, dc := range discovered[source] {
+			if first, found := hosts[dc.Host]; found {
+				log.Warnf("skipping %s from %s, it is already defined in %s", dc.Host, source, first)
+				continue
+			}
+
+			err := validateDiscoveredDevice(dc, cfg, reserved)
+			if err != nil {
+				log.Errorf("skipping %s from %s: %v", dc.Host, source, err)
+				continue
+			}
+
+			d, err := deviceFromDeviceConfig(dc, cfg)
+			if err != nil {
+				log.Errorf("skipping %s from %s: %v", dc.Host, source, err)
+				continue
+			}
+
+			hosts[dc.Host] = source
+			devs = append(devs, d)
+			counts[source]++
+		}
+	}
+
+	return devs, counts
+}
+
+func validateDiscoveredDevice(device *config.DeviceConfig, cfg *config.Config, reserved map[string]bool) error {
+	if _, found := cfg.Groups[device.Group]; device.Group != "" && !found {
+		return errors.Errorf("unknown profile %q", device.Group)
+	}
+
+	for k := range device.Labels {
+		if !config.IsValidLabelName(k) || k == "target" {
+			return errors.Errorf("invalid label name %q", k)
+		}
+		if reserved[k] {
+			return errors.Errorf("custom label %q is already used by a collector", k)
+		}
+	}
+
+	return nil
+}
+
 func deviceFromDeviceConfig(device *config.DeviceConfig, cfg *config.Config) (*connector.Device, error) {
+	if device.OSType != "" && !rpc.IsSupportedOSType(device.OSType) {
+		return nil, errors.Errorf("unsupported os_type %q for device %s", device.OSType, device.Host)
+	}
+
 	auth, err := authForDevice(device, cfg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not initialize config for device %s", device.Host)
 	}
 
-	port := "22"
-	host := device.Host
-	if strings.Contains(host, ":") {
-		d := strings.Split(host, ":")
-		host = d[0]
-		port = d[1]
+	host, port, err := cfg.AddressForDevice(device)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid host %s", device.Host)
 	}
 
 	return &connector.Device{
-		Host:         host,
-		Port:         port,
-		Auth:         auth,
-		DeviceConfig: device,
+		Host:          host,
+		Port:          port,
+		AddressFamily: cfg.AddressFamilyForDevice(device),
+		Auth:          auth,
+		DeviceConfig:  device,
 	}, nil
 }
 
@@ -60,11 +131,11 @@ func authForDevice(device *config.DeviceConfig, cfg *config.Config) (connector.A
 	}
 
 	if device.Password != nil {
-		return connector.AuthByPassword(user, *device.Password), nil
+		return connector.AuthByPassword(user, string(*device.Password)), nil
 	}
 
 	if cfg.Password != "" {
-		return connector.AuthByPassword(user, cfg.Password), nil
+		return connector.AuthByPassword(user, string(cfg.Password)), nil
 	}
 
 	return nil, errors.New("no valid authentication method available")
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/config"
+)
+
+func TestDevicesForInventory(t *testing.T) {
+	c := config.New()
+	c.Password = "secret"
+	c.Groups = map[string]*config.GroupConfig{"core": {Labels: map[string]string{"role": "core"}}}
+	c.Devices = []*config.DeviceConfig{{Host: "switch1"}}
+
+	static, err := devicesForConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	discovered := map[string][]*config.DeviceConfig{
+		"/inventory/b.csv": {
+			{Host: "switch2"},
+			{Host: "switch6", OSType: "Cisco"},
+		},
+		"/inventory/a.yml": {
+			{Host: "switch1"},
+			{Host: "switch2", Group: "core"},
+			{Host: "switch3", Group: "access"},
+			{Host: "switch4", Labels: map[string]string{"vrf": "default"}},
+			{Host: "switch5", Labels: map[string]string{"site": "hq"}},
+		},
+	}
+
+	devs, counts := devicesForInventory(c, static, discovered)
+
+	hosts := []string{}
+	for _, d := range devs {
+		hosts = append(hosts, d.DeviceConfig.Host)
+	}
+	// switch1 is already in the config, switch2 is taken from the first file in order of the names,
+	// switch3 has an unknown profile, switch4 a label used by a collector and switch6 an unsupported OS type
+	if want := []string{"switch1", "switch2", "switch5"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("got hosts %v, want %v", hosts, want)
+	}
+	if want := map[string]int{configSource: 1, "/inventory/a.yml": 2, "/inventory/b.csv": 0}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("got counts %v, want %v", counts, want)
+	}
+
+	if got := c.LabelsForDevice(devs[1].DeviceConfig); got["role"] != "core" {
+		t.Errorf("discovered device did not get the labels of its profile: %v", got)
+	}
+	if devs[0] != static[0] {
+		t.Error("device from the config was replaced")
+	}
+}
@@ -1,7 +1,6 @@
 package environment
 
 import (
-	log "github.com/sirupsen/logrus"
 	"github.com/slashdoom/aruba_exporter/collector"
 	"github.com/slashdoom/aruba_exporter/rpc"
 
@@ -21,27 +20,62 @@ var (
 )
 
 func init() {
+	collector.Register("environment", true, NewCollector)
+
 	lt := []string{"target", "slot_sensor", "module_type"}
 	lp := []string{"target", "power_slot", "product_number", "product_serial_number"}
 	lf := []string{"target", "fan_slot"}
 
-	TemperatureDesc = prometheus.NewDesc(prefix+"temperature", "Temperature in Celsius", lt, nil)
-	TemperatureStatusDesc = prometheus.NewDesc(prefix+"temperature_status", "Status of the sensor: normal = 1", lt, nil)
+	TemperatureDesc = collector.NewDesc(prefix+"temperature", "Temperature in Celsius", lt)
+	TemperatureStatusDesc = collector.NewDesc(prefix+"temperature_status", "Status of the sensor: normal = 1", lt)
 
-	PowerSupplyStatusDesc = prometheus.NewDesc(prefix+"power_supply_status", "Status of the power supply: ok = 1", lp, nil)
+	PowerSupplyStatusDesc = collector.NewDesc(prefix+"power_supply_status", "Status of the power supply: ok = 1", lp)
 
-	FanStatusDesc = prometheus.NewDesc(prefix+"fan_status", "Status of the fan", lf, nil)
-	FanSpeedDesc = prometheus.NewDesc(prefix+"fan_speed", "Speed of the fan: 0 = slow, 1 = fast", lf, nil)
-	FanDirectionDesc = prometheus.NewDesc(prefix+"fan_direction", "Direction of the fan: 0 = front-to-back, 1 = back-to-front", lf, nil)
-	FanRPMDesc = prometheus.NewDesc(prefix+"fan_rpm", "RPM of the fan", lf, nil)
+	FanStatusDesc = collector.NewDesc(prefix+"fan_status", "Status of the fan", lf)
+	FanSpeedDesc = collector.NewDesc(prefix+"fan_speed", "Speed of the fan: 0 = slow, 1 = fast", lf)
+	FanDirectionDesc = collector.NewDesc(prefix+"fan_direction", "Direction of the fan: 0 = front-to-back, 1 = back-to-front", lf)
+	FanRPMDesc = collector.NewDesc(prefix+"fan_rpm", "RPM of the fan", lf)
 }
 
 type environmentCollector struct {
+	parsers *collector.Parsers
 }
 
 // NewCollector creates a new collector
 func NewCollector() collector.RPCCollector {
-	return &environmentCollector{}
+	c := &environmentCollector{
+		parsers: collector.NewParsers("environment"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+// registerParsers registers the parsers of the switches. Controllers and Instant APs have no parsers,
+// so they are reported as unsupported without sending them any commands.
+func (c *environmentCollector) registerParsers() {
+	for _, osType := range []string{rpc.ArubaSwitch, rpc.ArubaCXSwitch} {
+		c.parsers.Register("temperature", &collector.Parser{
+			OSType:   osType,
+			Commands: []string{"show environment temperature"},
+			Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchTemp(out) },
+		})
+		c.parsers.Register("power", &collector.Parser{
+			OSType:   osType,
+			Commands: []string{"show environment power-supply"},
+			Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchPower(out) },
+		})
+	}
+	c.parsers.Register("fan", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show environment fan"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchFan(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *environmentCollector) Parsers() *collector.Parsers {
+	return c.parsers
 }
 
 func (*environmentCollector) Name() string {
@@ -53,60 +87,26 @@ func (c *environmentCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- TemperatureStatusDesc
 }
 
+// Collect collects the temperatures, power supplies and fans. A part which fails or is not implemented
+// for the device does not stop the others, the first error is returned.
 func (c *environmentCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	var (
-		outTemp    string
-		outPower   string
-		outFan     string
-		itemsTemp  map[string]Environment
-		itemsPower map[string]Environment
-		itemsFan   map[string]Environment
-		err        error
-	)
-
-	switch client.OSType {
-	//case "ArubaController":
-	//	out, err = client.RunCommand([]string{"show interface", "show interface counters"})
-	//	if err != nil {
-	//		return err
-	//	}
-	//case "ArubaInstant":
-	//	out, err = client.RunCommand([]string{"show interface counters"})
-	//	if err != nil {
-	//		return err
-	//	}
-	case "ArubaSwitch":
-		outTemp, err = client.RunCommand([]string{"show environment temperature"})
-		if err != nil {
-			return err
-		}
-
-		outPower, err = client.RunCommand([]string{"show environment power-supply"})
-		if err != nil {
-			return err
-		}
-	default:
-		outTemp, err = client.RunCommand([]string{"show environment temperature"})
-		if err != nil {
-			return err
-		}
-
-		outPower, err = client.RunCommand([]string{"show environment power-supply"})
-		if err != nil {
-			return err
-		}
-
-		outFan, err = client.RunCommand([]string{"show environment fan"})
-		if err != nil {
-			return err
+	var first error
+	for _, collect := range []func(*rpc.Client, chan<- prometheus.Metric, []string) error{c.collectTemperatures, c.collectPowerSupplies, c.collectFans} {
+		err := collect(client, ch, labelValues)
+		if err != nil && first == nil {
+			first = err
 		}
 	}
 
-	itemsTemp, err = c.ParseTemp(client.OSType, outTemp)
+	return first
+}
+
+func (c *environmentCollector) collectTemperatures(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	parsed, err := collector.Run(c.parsers, "temperature", client, ch, labelValues)
 	if err != nil {
-		log.Warnf("Parse environments failed for %s: %s\n", labelValues[0], err.Error())
-		return nil
+		return err
 	}
+	itemsTemp, _ := parsed.(map[string]Environment)
 
 	for envName, envData := range itemsTemp {
 		l := append(labelValues, envName, envData.TemperatureModuleType)
@@ -122,11 +122,15 @@ func (c *environmentCollector) Collect(client *rpc.Client, ch chan<- prometheus.
 		ch <- prometheus.MustNewConstMetric(TemperatureStatusDesc, prometheus.GaugeValue, float64(tempStatus), l...)
 	}
 
-	itemsPower, err = c.ParsePower(client.OSType, outPower)
+	return nil
+}
+
+func (c *environmentCollector) collectPowerSupplies(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	parsed, err := collector.Run(c.parsers, "power", client, ch, labelValues)
 	if err != nil {
-		log.Warnf("Parse environments failed for %s: %s\n", labelValues[0], err.Error())
-		return nil
+		return err
 	}
+	itemsPower, _ := parsed.(map[string]Environment)
 
 	for envName, envData := range itemsPower {
 		l := append(labelValues, envName, envData.PowerSupplyProductNumber, envData.PowerSupplySerialNumber)
@@ -141,10 +145,15 @@ func (c *environmentCollector) Collect(client *rpc.Client, ch chan<- prometheus.
 		ch <- prometheus.MustNewConstMetric(PowerSupplyStatusDesc, prometheus.GaugeValue, float64(powerStatus), l...)
 	}
 
-	itemsFan, err = c.ParseFan(client.OSType, outFan)
+	return nil
+}
+
+func (c *environmentCollector) collectFans(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	parsed, err := collector.Run(c.parsers, "fan", client, ch, labelValues)
 	if err != nil {
-		log.Warnf("Parse environments failed for %s: %s\n", labelValues[0], err.Error())
+		return err
 	}
+	itemsFan, _ := parsed.(map[string]Environment)
 
 	for envName, envData := range itemsFan {
 		l := append(labelValues, envName)
@@ -0,0 +1,71 @@
+package environment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type recordingConnection struct {
+	commands []string
+}
+
+func (r *recordingConnection) RunCommand(cmds []string) (string, error) {
+	r.commands = append(r.commands, cmds...)
+	return "", nil
+}
+
+func TestCollectCommands(t *testing.T) {
+	tests := []struct {
+		osType      string
+		commands    []string
+		err         string
+		unsupported []string
+	}{
+		{osType: rpc.ArubaController, err: "'temperature' is not implemented for ArubaController ", unsupported: []string{"temperature", "power", "fan"}},
+		{osType: rpc.ArubaInstant, err: "'temperature' is not implemented for ArubaInstant ", unsupported: []string{"temperature", "power", "fan"}},
+		{osType: rpc.ArubaSwitch, commands: []string{"show environment temperature", "show environment power-supply"}, err: "'fan' is not implemented for ArubaSwitch ", unsupported: []string{"fan"}},
+		{osType: rpc.ArubaCXSwitch, commands: []string{"show environment temperature", "show environment power-supply", "show environment fan"}, err: "no fan information found"},
+	}
+
+	for _, test := range tests {
+		conn := &recordingConnection{}
+		client := rpc.NewClientForConnection(conn, "device1", "")
+		client.OSType = test.osType
+
+		ch := make(chan prometheus.Metric, 10)
+		err := NewCollector().Collect(client, ch, []string{"device1"})
+		close(ch)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: got error %v, want %q", test.osType, err, test.err)
+		}
+		if !reflect.DeepEqual(conn.commands, test.commands) {
+			t.Errorf("%s: got commands %q, want %q", test.osType, conn.commands, test.commands)
+		}
+
+		var unsupported []string
+		for m := range ch {
+			if m.Desc() != collector.UnsupportedDesc {
+				continue
+			}
+			var d dto.Metric
+			err := m.Write(&d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, l := range d.GetLabel() {
+				if l.GetName() == "parser" {
+					unsupported = append(unsupported, l.GetValue())
+				}
+			}
+		}
+		if !reflect.DeepEqual(unsupported, test.unsupported) {
+			t.Errorf("%s: got unsupported parsers %v, want %v", test.osType, unsupported, test.unsupported)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package environment
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseTemp(f *testing.F) {
+	f.Add(" Sensors | Temp | Min | Max | Alarm\n ------- + ---- + --- + --- + -----\n Chassis | 25C  | 20C | 30C |\n")
+	c := NewCollector().(*environmentCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("temperature") {
+			items, _ := p.Parse(out)
+			golden.CheckValues(t, items, "Temperature")
+		}
+	})
+}
+
+func FuzzParsePower(f *testing.F) {
+	c := NewCollector().(*environmentCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("power") {
+			items, _ := p.Parse(out)
+			golden.CheckValues(t, items)
+		}
+	})
+}
+
+func FuzzParseFan(f *testing.F) {
+	c := NewCollector().(*environmentCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("fan") {
+			items, _ := p.Parse(out)
+			golden.CheckValues(t, items)
+		}
+	})
+}
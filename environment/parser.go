@@ -3,48 +3,11 @@ package environment
 import (
 	"errors"
 	log "github.com/sirupsen/logrus"
-	"github.com/slashdoom/aruba_exporter/rpc"
 	"github.com/slashdoom/aruba_exporter/util"
 	"regexp"
 	"strings"
 )
 
-func (c *environmentCollector) ParseTemp(ostype string, output string) (map[string]Environment, error) {
-	log.Debugf("OS: %s\n", ostype)
-	switch ostype {
-	case rpc.ArubaSwitch:
-		return c.ParseArubaSwitchTemp(output)
-	case rpc.ArubaCXSwitch:
-		return c.ParseArubaSwitchTemp(output)
-	default:
-		return nil, errors.New("'show environment' is not implemented for " + ostype)
-	}
-}
-
-func (c *environmentCollector) ParsePower(ostype string, output string) (map[string]Environment, error) {
-	log.Debugf("OS: %s\n", ostype)
-	switch ostype {
-	case rpc.ArubaSwitch:
-		return c.ParseArubaSwitchPower(output)
-	case rpc.ArubaCXSwitch:
-		return c.ParseArubaSwitchPower(output)
-	default:
-		return nil, errors.New("'show environment power-supply' is not implemented for " + ostype)
-	}
-}
-
-func (c *environmentCollector) ParseFan(ostype string, output string) (map[string]Environment, error) {
-	log.Debugf("OS: %s\n", ostype)
-	switch ostype {
-	case rpc.ArubaSwitch:
-		return c.ParseArubaSwitchFan(output)
-	case rpc.ArubaCXSwitch:
-		return c.ParseArubaSwitchFan(output)
-	default:
-		return nil, errors.New("'show environment fan' is not implemented for " + ostype)
-	}
-}
-
 func (c *environmentCollector) ParseArubaSwitchTemp(output string) (map[string]Environment, error) {
 	environments := make(map[string]Environment)
 
@@ -69,6 +32,10 @@ func (c *environmentCollector) ParseArubaSwitchTemp(output string) (map[string]E
 		moduleTypeMatch := moduleTypeRegex.FindStringSubmatch(line)
 		temperature := temperatureRegex.FindString(line)
 		statusMatch := statusRegex.FindStringSubmatch(line)
+		if moduleTypeMatch == nil || statusMatch == nil {
+			log.Tracef("skipping line: %s", line)
+			continue
+		}
 
 		temperature = strings.TrimSuffix(temperature, " C")
 		status := statusMatch[1]
@@ -108,6 +75,10 @@ func (c *environmentCollector) ParseArubaSwitchPower(output string) (map[string]
 		statusMatch := statusRegex.FindStringSubmatch(line)
 		productNumberMatch := productNumberRegex.FindStringSubmatch(line)
 		serialNumberMatch := serialNumberRegex.FindStringSubmatch(line)
+		if statusMatch == nil || productNumberMatch == nil {
+			log.Tracef("skipping line: %s", line)
+			continue
+		}
 		serialNumber := "N/A"
 		status := statusMatch[1]
 		productNumber := productNumberMatch[1]
@@ -160,6 +131,10 @@ func (c *environmentCollector) ParseArubaSwitchFan(output string) (map[string]En
 		statusMatch := statusRegex.FindStringSubmatch(line)
 		rpmMatch := rpmRegex.FindStringSubmatch(line)
 		direction := directionRegex.FindString(line)
+		if speedMatch == nil || statusMatch == nil || rpmMatch == nil {
+			log.Tracef("skipping line: %s", line)
+			continue
+		}
 		speed := speedMatch[1]
 		status := statusMatch[1]
 		rpm := rpmMatch[1]
@@ -0,0 +1,169 @@
+package fakedevice
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const moreprompt = "-- MORE --, next page: Space, next line: Enter, quit: Control-C"
+
+// cli emulates the command line of a device on a session
+type cli struct {
+	s      *Server
+	ch     ssh.Channel
+	r      *bufio.Reader
+	paging bool
+}
+
+func (s *Server) session(ch ssh.Channel) {
+	defer ch.Close()
+
+	c := &cli{
+		s:      s,
+		ch:     ch,
+		r:      bufio.NewReader(ch),
+		paging: s.PageLines > 0,
+	}
+	c.write(c.banner())
+	c.write(c.prompt())
+
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return
+		}
+
+		// the device echoes the input
+		c.write(line + "\r\n")
+
+		cmd := strings.TrimSpace(line)
+		if cmd == "" {
+			c.write(c.prompt())
+			continue
+		}
+		if cmd == "exit" {
+			return
+		}
+
+		n := c.s.recordCommand(cmd)
+		f := c.s.currentFaults()
+		if f.DisconnectAfter > 0 && n > f.DisconnectAfter {
+			return
+		}
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-c.s.closed:
+				return
+			}
+		}
+
+		err = c.run(cmd)
+		if err != nil {
+			return
+		}
+		c.write("\r\n" + c.prompt())
+	}
+}
+
+func (c *cli) run(cmd string) error {
+	if cmd == c.disablePaging() {
+		c.paging = false
+		return nil
+	}
+
+	out, found := c.s.Responses[cmd]
+	if !found {
+		c.write(c.invalidInput(cmd))
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	for i, line := range lines {
+		if c.paging && i > 0 && i%c.s.PageLines == 0 {
+			c.write(moreprompt)
+			key, err := c.r.ReadByte()
+			if err != nil {
+				return err
+			}
+			// overwrite the more prompt like the devices do
+			c.write("\r" + strings.Repeat(" ", len(moreprompt)) + "\r")
+			if key == 0x03 || key == 'q' {
+				return nil
+			}
+		}
+		c.write(line + "\r\n")
+	}
+
+	return nil
+}
+
+func (c *cli) readLine() (string, error) {
+	var b strings.Builder
+	for {
+		r, err := c.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\n':
+			return b.String(), nil
+		case '\r':
+			// \r\n is read as a single line break
+			if next, err := c.r.Peek(1); err == nil && next[0] == '\n' {
+				c.r.ReadByte()
+			}
+			return b.String(), nil
+		default:
+			b.WriteByte(r)
+		}
+	}
+}
+
+func (c *cli) write(s string) {
+	io.WriteString(c.ch, s)
+}
+
+func (c *cli) prompt() string {
+	switch c.s.OSType {
+	case "ArubaController":
+		return "(" + c.s.Hostname + ") #"
+	default:
+		return c.s.Hostname + "# "
+	}
+}
+
+func (c *cli) banner() string {
+	switch c.s.OSType {
+	case "ArubaSwitch":
+		return "\r\nHPE Aruba Networking switch\r\n\r\nPress any key to continue\r\n"
+	case "ArubaCXSwitch":
+		return "\r\nLast login: never\r\n\r\n"
+	default:
+		return "\r\n"
+	}
+}
+
+func (c *cli) disablePaging() string {
+	switch c.s.OSType {
+	case "ArubaSwitch", "ArubaCXSwitch":
+		return "no page"
+	default:
+		return "no paging"
+	}
+}
+
+func (c *cli) invalidInput(cmd string) string {
+	switch c.s.OSType {
+	case "ArubaCXSwitch":
+		return "Invalid input: " + cmd + "\r\n"
+	case "ArubaSwitch":
+		return "Invalid input: " + strings.Fields(cmd)[0] + "\r\n"
+	default:
+		return "% Invalid input detected at '^' marker.\r\n"
+	}
+}
@@ -0,0 +1,248 @@
+// Package fakedevice provides an in-process SSH server which emulates the CLI of Aruba devices.
+// Responses are read from the samples directory, so collectors can be tested without hardware.
+package fakedevice
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// Server is a fake Aruba device
+type Server struct {
+	// OSType is the OS type emulated (e.g. ArubaCXSwitch)
+	OSType   string
+	Hostname string
+	Username string
+	Password string
+
+	// Responses holds the output of each command, commands without a response are rejected as invalid input
+	Responses map[string]string
+	// PageLines is the number of lines after which the output is paged until paging is disabled, 0 never pages
+	PageLines int
+
+	mu       sync.Mutex
+	faults   Faults
+	listener net.Listener
+	config   *ssh.ServerConfig
+	conns    map[net.Conn]bool
+	commands []string
+	wg       sync.WaitGroup
+	closed   chan struct{}
+}
+
+// Faults are failures injected into the sessions of a server
+type Faults struct {
+	// Latency delays every response
+	Latency time.Duration
+	// DisconnectAfter closes the session after this number of commands received by the server, 0 never disconnects
+	DisconnectAfter int
+	// FailAuth rejects all logins
+	FailAuth bool
+}
+
+// New creates a server for an OS type with the default credentials admin/admin
+func New(osType string) *Server {
+	return &Server{
+		OSType:    osType,
+		Hostname:  defaultHostname(osType),
+		Username:  "admin",
+		Password:  "admin",
+		Responses: make(map[string]string),
+		PageLines: 24,
+		conns:     make(map[net.Conn]bool),
+		closed:    make(chan struct{}),
+	}
+}
+
+// LoadSamples adds the outputs of all commands for the OS type in a samples directory
+// (<dir>/<collector>/<OS type>/<command>) to the responses
+func (s *Server) LoadSamples(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", s.OSType, "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		s.Responses[filepath.Base(path)] = strings.ReplaceAll(string(b), "\r", "")
+	}
+
+	return nil
+}
+
+// Start listens on a random port of the loopback interface
+func (s *Server) Start() error {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.config = &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if s.currentFaults().FailAuth || c.User() != s.Username || string(password) != s.Password {
+				return nil, errors.New("access denied")
+			}
+			return nil, nil
+		},
+	}
+	s.config.AddHostKey(signer)
+
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+
+	return nil
+}
+
+// Addr gets the address the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// HostPort gets the host and port the server listens on
+func (s *Server) HostPort() (string, string) {
+	host, port, _ := net.SplitHostPort(s.Addr())
+	return host, port
+}
+
+// SetFaults changes the failures injected, this can be done while the server is running
+func (s *Server) SetFaults(f Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = f
+}
+
+func (s *Server) currentFaults() Faults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.faults
+}
+
+// Commands gets all commands received so far
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.commands...)
+}
+
+// Close stops the server and closes all connections
+func (s *Server) Close() {
+	close(s.closed)
+	s.listener.Close()
+
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		log.Debugf("fake device: handshake failed: %v", err)
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range requests {
+				switch req.Type {
+				case "pty-req", "shell", "window-change":
+					req.Reply(true, nil)
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+
+		s.session(channel)
+		return
+	}
+}
+
+func (s *Server) recordCommand(cmd string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands = append(s.commands, cmd)
+	return len(s.commands)
+}
+
+func defaultHostname(osType string) string {
+	switch osType {
+	case "ArubaCXSwitch":
+		return "switch"
+	case "ArubaSwitch":
+		return "HP-2930F"
+	case "ArubaController":
+		return "Aruba9004"
+	default:
+		return "ap-515"
+	}
+}
@@ -0,0 +1,197 @@
+package fakedevice_test
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/connector"
+	"github.com/slashdoom/aruba_exporter/fakedevice"
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func start(t *testing.T, osType string, setup func(*fakedevice.Server)) *fakedevice.Server {
+	t.Helper()
+
+	s := fakedevice.New(osType)
+	err := s.LoadSamples(golden.SamplesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setup != nil {
+		setup(s)
+	}
+	err = s.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func connect(s *fakedevice.Server, timeout int) (*connector.SSHConnection, error) {
+	host, port := s.HostPort()
+	cfg := config.New()
+	cfg.Timeout = timeout
+	cfg.CommandDelay = 0
+
+	return connector.NewSSSHConnection(&connector.Device{
+		Host:         host,
+		Port:         port,
+		Auth:         connector.AuthByPassword(s.Username, s.Password),
+		DeviceConfig: &config.DeviceConfig{Host: host},
+	}, cfg)
+}
+
+func TestIdentify(t *testing.T) {
+	versions := map[string]string{
+		rpc.ArubaInstant:    "8.7.1.6",
+		rpc.ArubaController: "8.7.0.0",
+		rpc.ArubaSwitch:     "16.10.0016",
+		rpc.ArubaCXSwitch:   "10.10.0002",
+	}
+
+	for _, osType := range rpc.OSTypes {
+		osType := osType
+		t.Run(osType, func(t *testing.T) {
+			t.Parallel()
+
+			s := start(t, osType, nil)
+			conn, err := connect(s, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			client := rpc.NewClient(conn, "info")
+			err = client.Identify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.OSType != osType || client.Version != versions[osType] {
+				t.Errorf("identified as %s %s, want %s %s", client.OSType, client.Version, osType, versions[osType])
+			}
+		})
+	}
+}
+
+func TestPaging(t *testing.T) {
+	t.Parallel()
+
+	s := start(t, rpc.ArubaSwitch, func(s *fakedevice.Server) {
+		s.PageLines = 3
+	})
+
+	client, err := ssh.Dial("tcp", s.Addr(), &ssh.ClientConfig{
+		User:            s.Username,
+		Auth:            []ssh.AuthMethod{ssh.Password(s.Password)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin, _ := session.StdinPipe()
+	stdout, _ := session.StdoutPipe()
+	err = session.Shell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(stdout)
+
+	readUntil(t, r, "# ")
+	io.WriteString(stdin, "show version\n")
+	readUntil(t, r, "-- MORE --")
+
+	io.WriteString(stdin, "q")
+	readUntil(t, r, "# ")
+	io.WriteString(stdin, "no page\n")
+	readUntil(t, r, "# ")
+	io.WriteString(stdin, "show version\n")
+	out := readUntil(t, r, "# ")
+	if strings.Contains(out, "-- MORE --") {
+		t.Errorf("output is paged after paging was disabled:\n%s", out)
+	}
+}
+
+func TestAuthFailure(t *testing.T) {
+	t.Parallel()
+
+	s := start(t, rpc.ArubaCXSwitch, func(s *fakedevice.Server) {
+		s.SetFaults(fakedevice.Faults{FailAuth: true})
+	})
+
+	_, err := connect(s, 2)
+	if err == nil {
+		t.Fatal("expected an authentication error")
+	}
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+
+	s := start(t, rpc.ArubaCXSwitch, nil)
+	conn, err := connect(s, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the latency is far above the timeout, closing the server ends the delayed response
+	conn.SetTimeout(200 * time.Millisecond)
+	s.SetFaults(fakedevice.Faults{Latency: time.Minute})
+
+	begin := time.Now()
+	_, err = conn.RunCommand([]string{"show version"})
+	if err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if d := time.Since(begin); d > 2*time.Second {
+		t.Errorf("timeout took %v", d)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	t.Parallel()
+
+	s := start(t, rpc.ArubaCXSwitch, nil)
+	conn, err := connect(s, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s.SetFaults(fakedevice.Faults{DisconnectAfter: len(s.Commands())})
+	_, err = conn.RunCommand([]string{"show version"})
+	if err != io.EOF {
+		t.Fatalf("got error %v, want EOF", err)
+	}
+}
+
+// readUntil reads the output of a session until it ends with a suffix
+func readUntil(t *testing.T, r *bufio.Reader, suffix string) string {
+	t.Helper()
+
+	var b strings.Builder
+	for !strings.HasSuffix(b.String(), suffix) {
+		c, err := r.ReadByte()
+		if err != nil {
+			t.Fatalf("%v after reading %q", err, b.String())
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package golden
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Seeds adds all outputs in a directory of the samples directory to the seed corpus of a fuzz target
+func Seeds(f *testing.F, dir string) {
+	f.Helper()
+
+	err := filepath.Walk(filepath.Join(SamplesDir, dir), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f.Add(strings.ReplaceAll(string(b), "\r", ""))
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+}
+
+// CheckValues checks that no number in a parser result is negative, except for the -1 sentinel
+// used for values which could not be parsed, and that there are no NaN or infinite values.
+// Fields which can be negative (e.g. a noise floor in dBm) are named in allowNegative.
+func CheckValues(t *testing.T, v interface{}, allowNegative ...string) {
+	t.Helper()
+
+	allowed := make(map[string]bool)
+	for _, name := range allowNegative {
+		allowed[name] = true
+	}
+
+	checkValue(t, reflect.ValueOf(v), "", allowed)
+}
+
+func checkValue(t *testing.T, v reflect.Value, path string, allowed map[string]bool) {
+	t.Helper()
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			checkValue(t, v.Elem(), path, allowed)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if allowed[f.Name] {
+				continue
+			}
+			checkValue(t, v.Field(i), path+"."+f.Name, allowed)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			checkValue(t, iter.Value(), path+"["+iter.Key().String()+"]", allowed)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			checkValue(t, v.Index(i), path, allowed)
+		}
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) || (f < 0 && f != -1) {
+			t.Errorf("invalid value %v for %s", f, path)
+		}
+	}
+}
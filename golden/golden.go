@@ -0,0 +1,117 @@
+// Package golden compares parser results for the outputs in the samples directory with expected files.
+// Run the tests with -update to write the expected files after a parser or sample changed.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/collector"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// SamplesDir is the samples directory relative to the package directories of the collectors
+const SamplesDir = "../samples"
+
+// result is what is written to a golden file
+type result struct {
+	Result interface{} `json:"result"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Sample reads the outputs of commands for an OS type from a directory in the samples directory
+// and joins them like a device session does. It reports false if a command has no sample.
+func Sample(t *testing.T, dir, osType string, commands []string) (string, bool) {
+	t.Helper()
+
+	outputs := make([]string, len(commands))
+	for i, cmd := range commands {
+		b, err := ioutil.ReadFile(filepath.Join(SamplesDir, dir, osType, cmd))
+		if os.IsNotExist(err) {
+			return "", false
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[i] = strings.ReplaceAll(string(b), "\r", "")
+	}
+
+	return strings.Join(outputs, "\n"), true
+}
+
+// Assert compares a parser result and error with the golden file testdata/<name>.json
+func Assert(t *testing.T, name string, v interface{}, err error) {
+	t.Helper()
+
+	r := result{Result: v}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	actual, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		t.Fatalf("could not marshal result: %v", err)
+	}
+	actual = append(actual, '\n')
+
+	compare(t, filepath.Join("testdata", name+".json"), actual)
+}
+
+// AssertText compares a text (e.g. a metrics exposition) with the golden file testdata/<name>.txt
+func AssertText(t *testing.T, name string, text string) {
+	t.Helper()
+
+	compare(t, filepath.Join("testdata", name+".txt"), []byte(text))
+}
+
+func compare(t *testing.T, path string, actual []byte) {
+	t.Helper()
+
+	if *update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, actual, 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read golden file, run the test with -update to create it: %v", err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("result differs from %s, run the test with -update if the change is expected\ngot:\n%s\nwant:\n%s", path, actual, expected)
+	}
+}
+
+// TestParsers runs every registered parser with a sample for its commands and compares the results with the golden files
+func TestParsers(t *testing.T, parsers *collector.Parsers, dir string) {
+	for _, name := range parsers.Names() {
+		for _, p := range parsers.Registered(name) {
+			p := p
+			id := p.OSType
+			if p.MinVersion != "" || p.MaxVersion != "" {
+				id += "_" + p.MinVersion + "-" + p.MaxVersion
+			}
+
+			t.Run(name+"/"+id, func(t *testing.T) {
+				out, found := Sample(t, dir, p.OSType, p.Commands)
+				if !found {
+					t.Skipf("no sample for %s", strings.Join(p.Commands, ", "))
+				}
+
+				v, err := p.Parse(out)
+				Assert(t, filepath.Join(name, id), v, err)
+			})
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParse(f *testing.F) {
+	golden.Seeds(f, "interface")
+	c := NewCollector().(*interfaceCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("interfaces") {
+			items, _ := p.Parse(out)
+			golden.CheckValues(t, items)
+		}
+	})
+}
+
+func FuzzParseArubaController(f *testing.F) {
+	golden.Seeds(f, "interface")
+	c := &interfaceCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		items, _ := c.ParseArubaController(out)
+		golden.CheckValues(t, items)
+	})
+}
+
+func FuzzParseArubaInstant(f *testing.F) {
+	golden.Seeds(f, "interface")
+	c := &interfaceCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		items, _ := c.ParseArubaInstant(out)
+		golden.CheckValues(t, items)
+	})
+}
+
+func FuzzParseArubaSwitch(f *testing.F) {
+	golden.Seeds(f, "interface")
+	c := &interfaceCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		items, _ := c.ParseArubaSwitch(out)
+		golden.CheckValues(t, items)
+	})
+}
+
+func FuzzParseArubaCXSwitch(f *testing.F) {
+	golden.Seeds(f, "interface")
+	c := &interfaceCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		items, _ := c.ParseArubaCXSwitch(out)
+		golden.CheckValues(t, items)
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/slashdoom/aruba_exporter/rpc"
 
 	"github.com/prometheus/client_golang/prometheus"
-	log "github.com/sirupsen/logrus"
 )
 
 const prefix string = "aruba_interface_"
@@ -33,35 +32,71 @@ var (
 )
 
 func init() {
+	collector.Register("interfaces", true, NewCollector)
+
 	l := []string{"target", "name", "description", "mac"}
 
-	rxBytesDesc = prometheus.NewDesc(prefix+"rx_bytes", "Received data in bytes", l, nil)
-	rxPacketsDesc = prometheus.NewDesc(prefix+"rx_packets", "Number of incoming packets", l, nil)
-	rxErrorsDesc = prometheus.NewDesc(prefix+"rx_errors", "Number of errors caused by incoming packets", l, nil)
-	rxDropsDesc = prometheus.NewDesc(prefix+"rx_drops", "Number of dropped incoming packets", l, nil)
-	rxUnicastDesc = prometheus.NewDesc(prefix+"rx_unicast", "Received unicast packets", l, nil)
-	rxBcastDesc = prometheus.NewDesc(prefix+"rx_broadcast", "Received broadcast packets", l, nil)
-	rxMcastDesc = prometheus.NewDesc(prefix+"rx_multicast", "Received multicast packets", l, nil)
-
-	txBytesDesc = prometheus.NewDesc(prefix+"tx_bytes", "Transmitted data in bytes", l, nil)
-	txPacketsDesc = prometheus.NewDesc(prefix+"tx_packets", "Number of outgoing packets", l, nil)
-	txErrorsDesc = prometheus.NewDesc(prefix+"tx_errors", "Number of errors caused by outgoing packets", l, nil)
-	txDropsDesc = prometheus.NewDesc(prefix+"tx_drops", "Number of dropped outgoing packets", l, nil)
-	txUnicastDesc = prometheus.NewDesc(prefix+"tx_unicast", "Transmitted unicast packets", l, nil)
-	txBcastDesc = prometheus.NewDesc(prefix+"tx_broadcast", "Transmitted broadcast packets", l, nil)
-	txMcastDesc = prometheus.NewDesc(prefix+"tx_multicast", "Transmitted multicast packets", l, nil)
-
-	adminStatusDesc = prometheus.NewDesc(prefix+"admin_up", "Admin operational status", l, nil)
-	operStatusDesc = prometheus.NewDesc(prefix+"up", "Interface operational status", l, nil)
-	errorStatusDesc = prometheus.NewDesc(prefix+"error_status", "Admin and operational status differ", l, nil)
+	rxBytesDesc = collector.NewDesc(prefix+"rx_bytes", "Received data in bytes", l)
+	rxPacketsDesc = collector.NewDesc(prefix+"rx_packets", "Number of incoming packets", l)
+	rxErrorsDesc = collector.NewDesc(prefix+"rx_errors", "Number of errors caused by incoming packets", l)
+	rxDropsDesc = collector.NewDesc(prefix+"rx_drops", "Number of dropped incoming packets", l)
+	rxUnicastDesc = collector.NewDesc(prefix+"rx_unicast", "Received unicast packets", l)
+	rxBcastDesc = collector.NewDesc(prefix+"rx_broadcast", "Received broadcast packets", l)
+	rxMcastDesc = collector.NewDesc(prefix+"rx_multicast", "Received multicast packets", l)
+
+	txBytesDesc = collector.NewDesc(prefix+"tx_bytes", "Transmitted data in bytes", l)
+	txPacketsDesc = collector.NewDesc(prefix+"tx_packets", "Number of outgoing packets", l)
+	txErrorsDesc = collector.NewDesc(prefix+"tx_errors", "Number of errors caused by outgoing packets", l)
+	txDropsDesc = collector.NewDesc(prefix+"tx_drops", "Number of dropped outgoing packets", l)
+	txUnicastDesc = collector.NewDesc(prefix+"tx_unicast", "Transmitted unicast packets", l)
+	txBcastDesc = collector.NewDesc(prefix+"tx_broadcast", "Transmitted broadcast packets", l)
+	txMcastDesc = collector.NewDesc(prefix+"tx_multicast", "Transmitted multicast packets", l)
+
+	adminStatusDesc = collector.NewDesc(prefix+"admin_up", "Admin operational status", l)
+	operStatusDesc = collector.NewDesc(prefix+"up", "Interface operational status", l)
+	errorStatusDesc = collector.NewDesc(prefix+"error_status", "Admin and operational status differ", l)
 }
 
 type interfaceCollector struct {
+	parsers *collector.Parsers
 }
 
 // NewCollector creates a new collector
 func NewCollector() collector.RPCCollector {
-	return &interfaceCollector{}
+	c := &interfaceCollector{
+		parsers: collector.NewParsers("interfaces"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *interfaceCollector) registerParsers() {
+	c.parsers.Register("interfaces", &collector.Parser{
+		OSType:   rpc.ArubaController,
+		Commands: []string{"show interface", "show interface counters"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaController(out) },
+	})
+	c.parsers.Register("interfaces", &collector.Parser{
+		OSType:   rpc.ArubaInstant,
+		Commands: []string{"show interface counters"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaInstant(out) },
+	})
+	c.parsers.Register("interfaces", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show interfaces ethernet all", "display interface"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitch(out) },
+	})
+	c.parsers.Register("interfaces", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show interface"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXSwitch(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *interfaceCollector) Parsers() *collector.Parsers {
+	return c.parsers
 }
 
 // Name returns the name of the collector
@@ -94,40 +129,11 @@ func (*interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect collects metrics from Aruba
 func (c *interfaceCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	var (
-		out   string
-		items map[string]Interface
-		err   error
-	)
-
-	switch client.OSType {
-	case "ArubaController":
-		out, err = client.RunCommand([]string{"show interface","show interface counters"})
-		if err != nil {
-			return err
-		}
-	case "ArubaInstant":
-		out, err = client.RunCommand([]string{"show interface counters"})
-		if err != nil {
-			return err
-		}
-	case "ArubaSwitch":
-		out, err = client.RunCommand([]string{"show interfaces ethernet all","display interface"})
-		if err != nil {
-			return err
-		}
-	default:
-		out, err = client.RunCommand([]string{"show interface"})
-		if err != nil {
-			return err
-		}
-	}
-
-	items, err = c.Parse(client.OSType, out)
+	parsed, err := collector.Run(c.parsers, "interfaces", client, ch, labelValues)
 	if err != nil {
-		log.Warnf("Parse interfaces failed for %s: %s\n", labelValues[0], err.Error())
-		return nil
+		return err
 	}
+	items := parsed.(map[string]Interface)
 
 	for intName, intData := range items {
 		l := append(labelValues, intName, intData.Description, intData.MacAddress)
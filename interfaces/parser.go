@@ -1,33 +1,14 @@
 package interfaces
 
 import (
-	"errors"
 	"regexp"
 	"strings"
 
-	"github.com/slashdoom/aruba_exporter/rpc"
 	"github.com/slashdoom/aruba_exporter/util"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// Parse parses cli output and tries to find interfaces with related stats
-func (c *interfaceCollector) Parse(ostype string, output string) (map[string]Interface, error) {
-	log.Debugf("OS: %s\n", ostype)
-	switch ostype {
-	case rpc.ArubaController:
-		return c.ParseArubaController(output)
-	case rpc.ArubaInstant:
-		return c.ParseArubaInstant(output)
-	case rpc.ArubaSwitch: 
-		return c.ParseArubaSwitch(output)
-	case rpc.ArubaCXSwitch:
-		return c.ParseArubaCXSwitch(output)
-	default:
-		return nil, errors.New("'show interface' is not implemented for " + ostype)
-	}
-}
-
 // Parses ArubaController cli output and tries to find interfaces with related stats
 func (c *interfaceCollector) ParseArubaController(output string) (map[string]Interface, error) {
 	interfaces := make(map[string]Interface)
@@ -320,14 +301,14 @@ func (c *interfaceCollector) ParseArubaSwitch(output string) (map[string]Interfa
 	macRegexp := regexp.MustCompile(`^\s+MAC Address\s+:\s+(.*?)\s*$`)
 	linkStatusRegexp := regexp.MustCompile(`^\s+Link Status\s+:\s+(Up|Down)\s*$`)
 	portEnabledRegexp := regexp.MustCompile(`^\s+Port Enabled\s+:\s+(Yes|No)\s*$`)
-	bytesRegexp := regexp.MustCompile(`\s+Bytes Rx\s+:\s+(.*?)\s+Bytes Tx\s+:\s+(.*?)\s*$`)
-	unicastRegexp := regexp.MustCompile(`\s+Unicast Rx\s+:\s+(.*?)\s+Unicast Tx\s+:\s+(.*?)\s*$`)
-	BandMcastRegexp := regexp.MustCompile(`\s+Bcast\/Mcast Rx\s+:\s+(.*?)\s+Bcast\/Mcast Tx\s+:\s+(.*?)\s*$`)
-	RxDropsRegexp := regexp.MustCompile(`\s+Discard Rx\s+:\s+(.*?)\s+Out Queue Len\s+:\s+(.*?)\s*$`)
-	TxDropsRegexp := regexp.MustCompile(`\s+FCS Rx\s+:\s+(.*?)\s+Drops Tx\s+:\s+(.*?)\s*$`)
-	RxErrorsRegexp := regexp.MustCompile(`\s+Total Rx Errors\s+:\s+(.*?)\s+Deferred Tx\s+:\s+(.*?)\s*$`)
-	TxLateCollnRegexp := regexp.MustCompile(`\s+Runts Rx\s+:\s+(.*?)\s+Late Colln Tx\s+:\s+(.*?)\s*$`)
-	TxExcessCollnRegexp := regexp.MustCompile(`\s+Giants Rx\s+:\s+(.*?)\s+Excessive Colln\s+:\s+(.*?)\s*$`)
+	bytesRegexp := regexp.MustCompile(`\s+Bytes Rx\s+:\s+(\d[\d,]*)\s+Bytes Tx\s+:\s+(\d[\d,]*)\s*$`)
+	unicastRegexp := regexp.MustCompile(`\s+Unicast Rx\s+:\s+(\d[\d,]*)\s+Unicast Tx\s+:\s+(\d[\d,]*)\s*$`)
+	BandMcastRegexp := regexp.MustCompile(`\s+Bcast\/Mcast Rx\s+:\s+(\d[\d,]*)\s+Bcast\/Mcast Tx\s+:\s+(\d[\d,]*)\s*$`)
+	RxDropsRegexp := regexp.MustCompile(`\s+Discard Rx\s+:\s+(\d[\d,]*)\s+Out Queue Len\s+:\s+(\d[\d,]*)\s*$`)
+	TxDropsRegexp := regexp.MustCompile(`\s+FCS Rx\s+:\s+(\d[\d,]*)\s+Drops Tx\s+:\s+(\d[\d,]*)\s*$`)
+	RxErrorsRegexp := regexp.MustCompile(`\s+Total Rx Errors\s+:\s+(\d[\d,]*)\s+Deferred Tx\s+:\s+(\d[\d,]*)\s*$`)
+	TxLateCollnRegexp := regexp.MustCompile(`\s+Runts Rx\s+:\s+(\d[\d,]*)\s+Late Colln Tx\s+:\s+(\d[\d,]*)\s*$`)
+	TxExcessCollnRegexp := regexp.MustCompile(`\s+Giants Rx\s+:\s+(\d[\d,]*)\s+Excessive Colln\s+:\s+(\d[\d,]*)\s*$`)
 
 	p2newIfRegexp := regexp.MustCompile(`\s*((?:Trk)?\d+\/?\d*)\s+current state:\s+(UP|DOWN)\s*$`)
 	p2inputTotalRegexp := regexp.MustCompile(`^\s*Input \(total\):\s+\d+ packets, \d+ bytes\s*$`)
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*interfaceCollector)
+	golden.TestParsers(t, c.parsers, "interface")
+}
@@ -0,0 +1,170 @@
+package inventory
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/slashdoom/aruba_exporter/config"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultRefreshInterval = 300
+
+// Discoverer reads targets from all inventory files in a set of directories
+// and keeps them up to date when the files change
+type Discoverer struct {
+	directories []string
+	interval    time.Duration
+
+	mu      sync.RWMutex
+	targets map[string][]*config.DeviceConfig
+
+	stop chan struct{}
+}
+
+// NewDiscoverer creates a new discoverer for the inventory config
+func NewDiscoverer(cfg *config.InventoryConfig) *Discoverer {
+	interval := defaultRefreshInterval
+	if cfg.RefreshInterval > 0 {
+		interval = cfg.RefreshInterval
+	}
+
+	return &Discoverer{
+		directories: cfg.Directories,
+		interval:    time.Duration(interval) * time.Second,
+		targets:     make(map[string][]*config.DeviceConfig),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Devices gets the device configs discovered so far by source file
+func (d *Discoverer) Devices() map[string][]*config.DeviceConfig {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	devices := make(map[string][]*config.DeviceConfig, len(d.targets))
+	for source, t := range d.targets {
+		devices[source] = t
+	}
+
+	return devices
+}
+
+// Refresh reads all inventory files and reports if the targets changed.
+// If a file can not be read, the targets last read from it are kept.
+func (d *Discoverer) Refresh() bool {
+	targets := make(map[string][]*config.DeviceConfig)
+
+	d.mu.RLock()
+	previous := d.targets
+	d.mu.RUnlock()
+
+	for _, dir := range d.directories {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			log.Errorf("could not read inventory directory %s: %v", dir, err)
+			for source, t := range previous {
+				if filepath.Dir(source) == filepath.Clean(dir) {
+					targets[source] = t
+				}
+			}
+			continue
+		}
+
+		for _, f := range files {
+			path := filepath.Join(dir, f.Name())
+			if f.IsDir() || !IsInventoryFile(path) {
+				continue
+			}
+
+			loaded, err := LoadFile(path)
+			if err != nil {
+				log.Errorf("could not load inventory file: %v", err)
+				if t, found := previous[path]; found {
+					targets[path] = t
+				}
+				continue
+			}
+
+			devices := make([]*config.DeviceConfig, len(loaded))
+			for i, t := range loaded {
+				devices[i] = t.DeviceConfig()
+			}
+			targets[path] = devices
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	changed := !reflect.DeepEqual(d.targets, targets)
+	d.targets = targets
+
+	return changed
+}
+
+// Run watches the inventory directories and calls onChange whenever the targets changed.
+// The directories are also read again every refresh interval in case an event was missed.
+func (d *Discoverer) Run(onChange func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Errorf("could not watch inventory directories, falling back to refresh interval: %v", err)
+	} else {
+		defer watcher.Close()
+		for _, dir := range d.directories {
+			err = watcher.Add(dir)
+			if err != nil {
+				log.Errorf("could not watch inventory directory %s: %v", dir, err)
+			}
+		}
+	}
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if watcher != nil {
+		events = watcher.Events
+		errs = watcher.Errors
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	// editors and config management tools often write files in several steps,
+	// so changes are only picked up once the directory has been quiet for a moment
+	debounce := time.NewTimer(0)
+	<-debounce.C
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case ev := <-events:
+			if IsInventoryFile(ev.Name) {
+				debounce.Reset(time.Second)
+			}
+		case err := <-errs:
+			log.Errorf("inventory watcher: %v", err)
+		case <-debounce.C:
+			d.refreshAndNotify(onChange)
+		case <-ticker.C:
+			d.refreshAndNotify(onChange)
+		}
+	}
+}
+
+// Stop stops watching the inventory directories
+func (d *Discoverer) Stop() {
+	close(d.stop)
+}
+
+func (d *Discoverer) refreshAndNotify(onChange func()) {
+	if d.Refresh() {
+		log.Infoln("Inventory changed")
+		onChange()
+	}
+}
@@ -0,0 +1,90 @@
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/inventory"
+)
+
+func hosts(devices map[string][]*config.DeviceConfig) map[string][]string {
+	h := make(map[string][]string)
+	for source, dcs := range devices {
+		for _, dc := range dcs {
+			h[filepath.Base(source)] = append(h[filepath.Base(source)], dc.Host)
+		}
+	}
+
+	return h
+}
+
+func TestRefresh(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "a.yml", "- host: switch1\n  profile: core\n")
+	write(t, dir, "b.csv", "host\nswitch2\nswitch3\n")
+	write(t, dir, "notes.txt", "switch4\n")
+	os.Mkdir(filepath.Join(dir, "sub.yml"), 0755)
+
+	d := inventory.NewDiscoverer(&config.InventoryConfig{Directories: []string{dir}})
+	if !d.Refresh() {
+		t.Error("first refresh did not report a change")
+	}
+
+	got := hosts(d.Devices())
+	if len(got) != 2 || len(got["a.yml"]) != 1 || len(got["b.csv"]) != 2 {
+		t.Errorf("got %v", got)
+	}
+	if group := d.Devices()[filepath.Join(dir, "a.yml")][0].Group; group != "core" {
+		t.Errorf("profile became group %q, want core", group)
+	}
+
+	if d.Refresh() {
+		t.Error("refresh without changes reported a change")
+	}
+
+	// a broken file keeps the targets last read from it
+	write(t, dir, "a.yml", "- host: [\n")
+	if d.Refresh() {
+		t.Error("refresh with a broken file reported a change")
+	}
+	if got := hosts(d.Devices()); len(got["a.yml"]) != 1 {
+		t.Errorf("targets of the broken file were dropped: %v", got)
+	}
+
+	os.Remove(filepath.Join(dir, "b.csv"))
+	if !d.Refresh() {
+		t.Error("removing a file did not report a change")
+	}
+	if got := hosts(d.Devices()); len(got) != 1 {
+		t.Errorf("got %v after removing a file", got)
+	}
+}
+
+func TestRunReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "a.yml", "- host: switch1\n")
+
+	d := inventory.NewDiscoverer(&config.InventoryConfig{Directories: []string{dir}, RefreshInterval: 3600})
+	d.Refresh()
+
+	changed := make(chan struct{}, 10)
+	go d.Run(func() { changed <- struct{}{} })
+	defer d.Stop()
+
+	// give the watcher time to start before changing the directory
+	time.Sleep(200 * time.Millisecond)
+	write(t, dir, "b.json", `[{"host": "switch2"}]`)
+
+	select {
+	case <-changed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("change of the inventory directory was not picked up")
+	}
+
+	if got := hosts(d.Devices()); len(got["b.json"]) != 1 || got["b.json"][0] != "switch2" {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,148 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/config"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Target is a device read from an inventory file
+type Target struct {
+	Host    string            `yaml:"host" json:"host"`
+	Labels  map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Profile string            `yaml:"profile,omitempty" json:"profile,omitempty"`
+	OSType  string            `yaml:"os_type,omitempty" json:"os_type,omitempty"`
+}
+
+// DeviceConfig converts the target to a device config. The profile is the name of a group in the config.
+func (t *Target) DeviceConfig() *config.DeviceConfig {
+	return &config.DeviceConfig{
+		Host:   t.Host,
+		Group:  t.Profile,
+		Labels: t.Labels,
+		OSType: t.OSType,
+	}
+}
+
+// IsInventoryFile checks if a file has one of the supported extensions
+func IsInventoryFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml", ".json", ".csv":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadFile reads the targets from a YAML, JSON or CSV file
+func LoadFile(path string) ([]*Target, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*Target
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		targets, err = parseYAML(b)
+	case ".json":
+		targets, err = parseJSON(b)
+	case ".csv":
+		targets, err = parseCSV(b)
+	default:
+		return nil, errors.Errorf("unsupported inventory file type %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path)
+	}
+
+	for i, t := range targets {
+		if t == nil || t.Host == "" {
+			return nil, errors.Errorf("target %d in %s has no host", i+1, path)
+		}
+	}
+
+	return targets, nil
+}
+
+func parseYAML(b []byte) ([]*Target, error) {
+	targets := []*Target{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	err := dec.Decode(&targets)
+	if err == io.EOF {
+		return targets, nil
+	}
+
+	return targets, err
+}
+
+func parseJSON(b []byte) ([]*Target, error) {
+	targets := []*Target{}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&targets)
+	if err == io.EOF {
+		return targets, nil
+	}
+
+	return targets, err
+}
+
+// parseCSV reads targets from a CSV file with a header row. The columns host, profile and os_type
+// are used for the target, all other columns become labels.
+func parseCSV(b []byte) ([]*Target, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []*Target{}, nil
+	}
+
+	header := records[0]
+	hasHost := false
+	for _, h := range header {
+		if strings.TrimSpace(h) == "host" {
+			hasHost = true
+		}
+	}
+	if !hasHost {
+		return nil, errors.New("missing host column in header")
+	}
+
+	targets := make([]*Target, 0, len(records)-1)
+	for _, record := range records[1:] {
+		t := &Target{Labels: make(map[string]string)}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			switch name := strings.TrimSpace(header[i]); name {
+			case "host":
+				t.Host = value
+			case "profile":
+				t.Profile = value
+			case "os_type":
+				t.OSType = value
+			default:
+				t.Labels[name] = value
+			}
+		}
+		targets = append(targets, t)
+	}
+
+	return targets, nil
+}
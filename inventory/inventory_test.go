@@ -0,0 +1,123 @@
+package inventory_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/inventory"
+)
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	want := []*inventory.Target{
+		{Host: "switch1", Labels: map[string]string{"site": "hq"}, Profile: "core", OSType: "ArubaCXSwitch"},
+		{Host: "switch2", Labels: map[string]string{"site": "branch"}},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "targets.yml",
+			content: `
+- host: switch1
+  labels:
+    site: hq
+  profile: core
+  os_type: ArubaCXSwitch
+- host: switch2
+  labels:
+    site: branch
+`,
+		},
+		{
+			name:    "targets.json",
+			content: `[{"host": "switch1", "labels": {"site": "hq"}, "profile": "core", "os_type": "ArubaCXSwitch"}, {"host": "switch2", "labels": {"site": "branch"}}]`,
+		},
+		{
+			name:    "targets.CSV",
+			content: "host, profile, os_type, site\nswitch1, core, ArubaCXSwitch, hq\nswitch2, , , branch\n",
+		},
+	}
+
+	dir := t.TempDir()
+	for _, test := range tests {
+		targets, err := inventory.LoadFile(write(t, dir, test.name, test.content))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(targets, want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, targets, want)
+		}
+	}
+}
+
+func TestLoadFileEmpty(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"empty.yml", "empty.csv"} {
+		targets, err := inventory.LoadFile(write(t, dir, name, ""))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if len(targets) != 0 {
+			t.Errorf("%s: got %d targets", name, len(targets))
+		}
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown.yml", content: "- host: switch1\n  hostname: x\n", wantErr: "field hostname not found"},
+		{name: "unknown.json", content: `[{"host": "switch1", "hostname": "x"}]`, wantErr: `unknown field "hostname"`},
+		{name: "nohost.yml", content: "- profile: core\n", wantErr: "target 1 in"},
+		{name: "nohost.csv", content: "name,site\nswitch1,hq\n", wantErr: "missing host column in header"},
+		{name: "columns.csv", content: "host,site\nswitch1\n", wantErr: "wrong number of fields"},
+		{name: "targets.txt", content: "switch1\n", wantErr: "unsupported inventory file type .txt"},
+	}
+
+	dir := t.TempDir()
+	for _, test := range tests {
+		_, err := inventory.LoadFile(write(t, dir, test.name, test.content))
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestIsInventoryFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"a.yml":    true,
+		"a.YAML":   true,
+		"a.json":   true,
+		"a.csv":    true,
+		"a.txt":    false,
+		"a.yml~":   false,
+		".a.swp":   false,
+		"yml":      false,
+		"dir/a.js": false,
+	} {
+		if got := inventory.IsInventoryFile(path); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var inventoryDevicesDesc *prometheus.Desc
+
+func init() {
+	inventoryDevicesDesc = prometheus.NewDesc(prefix+"inventory_devices", "Number of active devices by source", []string{"source"}, nil)
+}
+
+type inventoryCollector struct {
+	counts map[string]int
+}
+
+func newInventoryCollector(counts map[string]int) *inventoryCollector {
+	return &inventoryCollector{
+		counts: counts,
+	}
+}
+
+// Describe implements prometheus.Collector interface
+func (c *inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inventoryDevicesDesc
+}
+
+// Collect implements prometheus.Collector interface
+func (c *inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	for source, count := range c.counts {
+		ch <- prometheus.MustNewConstMetric(inventoryDevicesDesc, prometheus.GaugeValue, float64(count), source)
+	}
+}
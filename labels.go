@@ -0,0 +1,147 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/connector"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// customLabels appends the custom labels configured for a device to all of its metrics.
+// The label keys are the union of the keys of all devices, so every descriptor has the same labels.
+type customLabels struct {
+	keys  []string
+	cfg   *config.Config
+	mu    sync.Mutex
+	descs map[*prometheus.Desc]*prometheus.Desc
+}
+
+func customLabelsForDevices(devices []*connector.Device, cfg *config.Config) *customLabels {
+	keys := make(map[string]bool)
+	for _, d := range devices {
+		for k := range cfg.LabelsForDevice(d.DeviceConfig) {
+			keys[k] = true
+		}
+	}
+
+	l := &customLabels{
+		keys:  make([]string, 0, len(keys)),
+		cfg:   cfg,
+		descs: make(map[*prometheus.Desc]*prometheus.Desc),
+	}
+	for k := range keys {
+		l.keys = append(l.keys, k)
+	}
+	sort.Strings(l.keys)
+
+	return l
+}
+
+// validateLabelKeys makes sure no custom label clashes with a label used by a collector
+func validateLabelKeys(devices []*connector.Device, cfg *config.Config) error {
+	reserved := collector.LabelNames()
+	for _, cc := range cfg.CustomCollectors {
+		for _, m := range cc.Metrics {
+			for _, l := range m.Labels {
+				reserved[l] = true
+			}
+		}
+	}
+	for _, k := range customLabelsForDevices(devices, cfg).keys {
+		if reserved[k] {
+			return errors.Errorf("custom label %q is already used by a collector", k)
+		}
+	}
+
+	return nil
+}
+
+func (l *customLabels) values(device *connector.Device) []string {
+	labels := l.cfg.LabelsForDevice(device.DeviceConfig)
+
+	values := make([]string, len(l.keys))
+	for i, k := range l.keys {
+		values[i] = labels[k]
+	}
+
+	return values
+}
+
+func (l *customLabels) desc(d *prometheus.Desc) *prometheus.Desc {
+	if len(l.keys) == 0 {
+		return d
+	}
+
+	info, found := collector.LookupDesc(d)
+	if !found {
+		return d
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if wrapped, found := l.descs[d]; found {
+		return wrapped
+	}
+
+	labels := append(append([]string{}, info.VariableLabels...), l.keys...)
+	wrapped := prometheus.NewDesc(info.FqName, info.Help, labels, nil)
+	l.descs[d] = wrapped
+
+	return wrapped
+}
+
+func (l *customLabels) metric(m prometheus.Metric, values []string) prometheus.Metric {
+	if len(l.keys) == 0 {
+		return m
+	}
+
+	info, found := collector.LookupDesc(m.Desc())
+	if !found {
+		return m
+	}
+
+	pb := &dto.Metric{}
+	err := m.Write(pb)
+	if err != nil {
+		return prometheus.NewInvalidMetric(m.Desc(), err)
+	}
+
+	pairs := make(map[string]string)
+	for _, p := range pb.Label {
+		pairs[p.GetName()] = p.GetValue()
+	}
+	labelValues := make([]string, 0, len(info.VariableLabels)+len(values))
+	for _, name := range info.VariableLabels {
+		labelValues = append(labelValues, pairs[name])
+	}
+	labelValues = append(labelValues, values...)
+
+	var (
+		valueType prometheus.ValueType
+		value     float64
+	)
+	switch {
+	case pb.Gauge != nil:
+		valueType, value = prometheus.GaugeValue, pb.Gauge.GetValue()
+	case pb.Counter != nil:
+		valueType, value = prometheus.CounterValue, pb.Counter.GetValue()
+	case pb.Untyped != nil:
+		valueType, value = prometheus.UntypedValue, pb.Untyped.GetValue()
+	default:
+		return m
+	}
+
+	wrapped, err := prometheus.NewConstMetric(l.desc(m.Desc()), valueType, value, labelValues...)
+	if err != nil {
+		return prometheus.NewInvalidMetric(m.Desc(), err)
+	}
+
+	return wrapped
+}
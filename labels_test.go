@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/fakedevice"
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+// closedPort gets a local port nothing listens on
+func closedPort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	return port
+}
+
+func TestCustomLabels(t *testing.T) {
+	t.Parallel()
+
+	port := closedPort(t)
+	out := scrapeWithDurations(t, rpc.ArubaCXSwitch, fakedevice.Faults{}, func(c *config.Config) {
+		for _, name := range config.Features() {
+			c.Features[name] = name == "system"
+		}
+		c.Labels = map[string]string{"site": "hq", "role": "access"}
+		c.Groups = map[string]*config.GroupConfig{"core": {Labels: map[string]string{"role": "core"}}}
+		c.Devices[0].Group = "core"
+		c.Devices[0].Labels = map[string]string{"rack": "r1"}
+		c.Devices = append(c.Devices, &config.DeviceConfig{Host: "localhost", Port: &port, Labels: map[string]string{"site": "dc"}})
+	})
+
+	tests := []struct {
+		name   string
+		metric string
+		labels string
+	}{
+		{name: "up", metric: "aruba_up", labels: `rack="r1",role="core",site="hq",target="127.0.0.1"`},
+		{name: "scrape duration", metric: "aruba_collector_duration_seconds", labels: `rack="r1",role="core",site="hq",target="127.0.0.1"`},
+		{name: "collector duration", metric: "aruba_collect_duration_seconds", labels: `collector="System",rack="r1",role="core",site="hq",target="127.0.0.1"`},
+		{name: "collector metric", metric: "aruba_system_cpu_used_percent", labels: `rack="r1",role="core",site="hq",target="127.0.0.1",type="total"`},
+		{name: "missing keys are empty", metric: "aruba_up", labels: `rack="",role="access",site="dc",target="localhost"`},
+		{name: "missing keys are empty on the scrape duration", metric: "aruba_collector_duration_seconds", labels: `rack="",role="access",site="dc",target="localhost"`},
+	}
+
+	for _, test := range tests {
+		r := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(test.metric+"{"+test.labels+"}") + ` \S+$`)
+		if !r.MatchString(out) {
+			t.Errorf("%s: no %s{%s} in\n%s", test.name, test.metric, test.labels, out)
+		}
+	}
+
+	for _, l := range strings.Split(out, "\n") {
+		if strings.HasPrefix(l, "aruba_") && strings.Contains(l, `target="`) && !strings.Contains(l, `site="`) {
+			t.Errorf("metric without custom labels: %s", l)
+		}
+	}
+}
+
+func TestCustomLabelKeys(t *testing.T) {
+	c := config.New()
+	c.Password = config.Secret("secret")
+	c.Labels = map[string]string{"site": "hq"}
+	c.Groups = map[string]*config.GroupConfig{"core": {Labels: map[string]string{"role": "core"}}}
+	c.Devices = []*config.DeviceConfig{
+		{Host: "switch1", Group: "core"},
+		{Host: "switch2", Labels: map[string]string{"rack": "r2"}},
+		{Host: "switch3", Group: "unknown"},
+	}
+
+	devs, err := devicesForConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := customLabelsForDevices(devs, c)
+	if want := []string{"rack", "role", "site"}; !reflect.DeepEqual(l.keys, want) {
+		t.Errorf("got keys %v, want %v", l.keys, want)
+	}
+
+	want := [][]string{
+		{"", "core", "hq"},
+		{"r2", "", "hq"},
+		{"", "", "hq"},
+	}
+	for i, d := range devs {
+		if got := l.values(d); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("%s: got values %v, want %v", d.DeviceConfig.Host, got, want[i])
+		}
+	}
+
+	if l := customLabelsForDevices(devs[:0], c); len(l.keys) != 0 {
+		t.Errorf("got keys %v without devices", l.keys)
+	}
+}
+
+func TestValidateLabelKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		custom []*config.CustomCollectorConfig
+		err    string
+	}{
+		{name: "no clash", labels: map[string]string{"site": "hq"}},
+		{name: "built-in label", labels: map[string]string{"collector": "x"}, err: `custom label "collector" is already used by a collector`},
+		{name: "label of a collector", labels: map[string]string{"type": "x"}, err: `custom label "type" is already used by a collector`},
+		{
+			name:   "label of a custom collector",
+			labels: map[string]string{"vlan": "x"},
+			custom: []*config.CustomCollectorConfig{{Name: "vlans", Metrics: []*config.CustomMetricConfig{{Name: "vlan_up", Labels: []string{"vlan"}}}}},
+			err:    `custom label "vlan" is already used by a collector`,
+		},
+	}
+
+	for _, test := range tests {
+		c := config.New()
+		c.Password = config.Secret("secret")
+		c.CustomCollectors = test.custom
+		c.Devices = []*config.DeviceConfig{{Host: "switch1"}, {Host: "switch2", Labels: test.labels}}
+
+		devs, err := devicesForConfig(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = validateLabelKeys(devs, c)
+		if test.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package lag
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseLAGs(f *testing.F) {
+	golden.Seeds(f, "lag")
+	c := NewCollector().(*lagCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("lags") {
+			lags, _ := p.Parse(out)
+			golden.CheckValues(t, lags)
+		}
+	})
+}
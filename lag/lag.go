@@ -0,0 +1,21 @@
+package lag
+
+// LAG is a link aggregation (CX: lag<n>, AOS-S: trunk Trk<n>). Active is -1 if the state of the members is unknown,
+// e.g. for static AOS-S trunks. PartnerSystemIDs are the LACP system IDs of the partners, more than one means
+// the members are connected to different devices.
+type LAG struct {
+	Name             string
+	Active           float64
+	PartnerSystemIDs []string
+	Members          []*Member
+}
+
+// Member is a port of a LAG. Active is 1 if it is collecting and distributing, 0 if not and -1 if unknown.
+// ActorState and PartnerState are the IEEE 802.1AX state bits of the LACPDUs, -1 if unknown.
+type Member struct {
+	Interface       string
+	Active          float64
+	ActorState      float64
+	PartnerState    float64
+	PartnerSystemID string
+}
@@ -0,0 +1,108 @@
+package lag
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_lag_"
+
+var (
+	MembersConfiguredDesc  *prometheus.Desc
+	MembersActiveDesc      *prometheus.Desc
+	InfoDesc               *prometheus.Desc
+	MemberActorStateDesc   *prometheus.Desc
+	MemberPartnerStateDesc *prometheus.Desc
+)
+
+func init() {
+	collector.Register("lag", false, NewCollector)
+
+	l := []string{"target", "lag"}
+
+	MembersConfiguredDesc = collector.NewDesc(prefix+"members_configured", "Number of ports configured as members of the LAG", l)
+	MembersActiveDesc = collector.NewDesc(prefix+"members_active", "Number of members collecting and distributing", l)
+	InfoDesc = collector.NewDesc(prefix+"info", "LACP system ID of the partner of the LAG", append(l, "partner_system_id"))
+	MemberActorStateDesc = collector.NewDesc(prefix+"member_actor_lacp_state", "LACP state bits of the member (1 activity, 2 short timeout, 4 aggregation, 8 synchronization, 16 collecting, 32 distributing, 64 defaulted, 128 expired)", append(l, "interface"))
+	MemberPartnerStateDesc = collector.NewDesc(prefix+"member_partner_lacp_state", "LACP state bits of the partner of the member (same bits as the actor)", append(l, "interface"))
+}
+
+type lagCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &lagCollector{
+		parsers: collector.NewParsers("lag"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *lagCollector) registerParsers() {
+	c.parsers.Register("lags", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show lacp aggregates", "show lacp interfaces"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXLAGs(out) },
+	})
+	c.parsers.Register("lags", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show trunks", "show lacp", "show lacp peer"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchLAGs(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *lagCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*lagCollector) Name() string {
+	return "LAG"
+}
+
+func (c *lagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- MembersConfiguredDesc
+	ch <- MembersActiveDesc
+	ch <- InfoDesc
+	ch <- MemberActorStateDesc
+	ch <- MemberPartnerStateDesc
+}
+
+func (c *lagCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("lags", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "lags", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+
+	for _, lag := range parsed.([]*LAG) {
+		l := append(labelValues, lag.Name)
+
+		ch <- prometheus.MustNewConstMetric(MembersConfiguredDesc, prometheus.GaugeValue, float64(len(lag.Members)), l...)
+		if lag.Active >= 0 {
+			ch <- prometheus.MustNewConstMetric(MembersActiveDesc, prometheus.GaugeValue, lag.Active, l...)
+		}
+		for _, id := range lag.PartnerSystemIDs {
+			ch <- prometheus.MustNewConstMetric(InfoDesc, prometheus.GaugeValue, 1, append(l, id)...)
+		}
+
+		for _, m := range lag.Members {
+			if m.ActorState >= 0 {
+				ch <- prometheus.MustNewConstMetric(MemberActorStateDesc, prometheus.GaugeValue, m.ActorState, append(l, m.Interface)...)
+			}
+			if m.PartnerState >= 0 {
+				ch <- prometheus.MustNewConstMetric(MemberPartnerStateDesc, prometheus.GaugeValue, m.PartnerState, append(l, m.Interface)...)
+			}
+		}
+	}
+
+	return nil
+}
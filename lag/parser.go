@@ -0,0 +1,255 @@
+package lag
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LACP state bits in the order of IEEE 802.1AX
+const (
+	stateActivity = 1 << iota
+	stateTimeout
+	stateAggregation
+	stateSynchronization
+	stateCollecting
+	stateDistributing
+	stateDefaulted
+	stateExpired
+)
+
+var (
+	fieldRegexp     = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z ]*?)\s*:\s*(.*?)\s*$`)
+	flagsRegexp     = regexp.MustCompile(`^[APSLFINOCDXE]+$`)
+	separatorRegexp = regexp.MustCompile(`^[\s+-]*-{3,}[\s+-]*$`)
+	dashesRegexp    = regexp.MustCompile(`-+`)
+	zeroIDRegexp    = regexp.MustCompile(`^[0:.\-]*$`)
+)
+
+// flagBits are the CX abbreviations of the LACP states which set a bit, the others (P, L, I, O) are their unset counterparts
+var flagBits = map[rune]int{
+	'A': stateActivity,
+	'S': stateTimeout,
+	'F': stateAggregation,
+	'N': stateSynchronization,
+	'C': stateCollecting,
+	'D': stateDistributing,
+	'E': stateDefaulted,
+	'X': stateExpired,
+}
+
+// ParseArubaCXLAGs parses "show lacp aggregates" and "show lacp interfaces". The aggregates have the configured
+// members, the actor and partner tables of the interfaces their LACP state flags and the partner system ID.
+func (c *lagCollector) ParseArubaCXLAGs(output string) ([]*LAG, error) {
+	lags := newLAGs()
+
+	var current *LAG
+	var partner bool
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		switch {
+		case strings.HasPrefix(line, "Actor details"):
+			partner = false
+			continue
+		case strings.HasPrefix(line, "Partner details"):
+			partner = true
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 8 && flagsRegexp.MatchString(fields[4]) {
+			m := lags.member(fields[1], fields[0])
+			state := flags(fields[4])
+			if partner {
+				m.PartnerState = state
+				m.PartnerSystemID = fields[5]
+				continue
+			}
+
+			m.ActorState = state
+			m.Active = 0
+			if int(state)&(stateCollecting|stateDistributing) == stateCollecting|stateDistributing {
+				m.Active = 1
+			}
+			continue
+		}
+
+		f := fieldRegexp.FindStringSubmatch(line)
+		if f == nil {
+			continue
+		}
+
+		switch strings.ToLower(f[1]) {
+		case "aggregate name":
+			current = lags.lag(f[2])
+		case "interfaces":
+			if current == nil {
+				continue
+			}
+			for _, intf := range strings.Fields(f[2]) {
+				lags.member(current.Name, intf)
+			}
+		}
+	}
+
+	return lags.result(), nil
+}
+
+// ParseArubaSwitchLAGs parses "show trunks", "show lacp" and "show lacp peer" of AOS-S. The trunks have the
+// configured members, "show lacp" the state of the members of LACP trunks and "show lacp peer" the partner system IDs.
+// The tables are told apart by their headers.
+func (c *lagCollector) ParseArubaSwitchLAGs(output string) ([]*LAG, error) {
+	lags := newLAGs()
+
+	var spans [][]int
+	var names []string
+	header := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if separatorRegexp.MatchString(line) {
+			spans = dashesRegexp.FindAllStringIndex(line, -1)
+			names = make([]string, len(spans))
+			for _, h := range header {
+				for i, name := range splitColumns(h, spans) {
+					names[i] = strings.TrimSpace(names[i] + " " + name)
+				}
+			}
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			spans = nil
+			header = header[:0]
+			continue
+		}
+
+		if spans == nil {
+			header = append(header, line)
+			continue
+		}
+
+		row := make(map[string]string)
+		for i, value := range splitColumns(line, spans) {
+			name := strings.ToLower(names[i])
+			if _, found := row[name]; found {
+				name = "trunk " + name
+			}
+			row[name] = value
+		}
+
+		// rows without a port continue the row above
+		switch {
+		case row["group"] != "" && row["port"] != "":
+			// show trunks
+			lags.member(row["group"], row["port"])
+		case row["trunk group"] != "" && row["port"] != "":
+			// show lacp, the state is unknown if a column is missing
+			m := lags.member(row["trunk group"], row["port"])
+			if row["port status"] == "" || row["partner"] == "" {
+				continue
+			}
+			m.Active = 0
+			if strings.EqualFold(row["port status"], "up") && strings.EqualFold(row["partner"], "yes") {
+				m.Active = 1
+			}
+		case row["local trunk"] != "" && row["local port"] != "":
+			// show lacp peer
+			m := lags.member(row["local trunk"], row["local port"])
+			m.PartnerSystemID = row["system id"]
+		}
+	}
+
+	return lags.result(), nil
+}
+
+// flags converts the CX LACP state abbreviations to the state bits
+func flags(s string) float64 {
+	state := 0
+	for _, r := range s {
+		state |= flagBits[r]
+	}
+
+	return float64(state)
+}
+
+// lagsByName collects LAGs and their members in the order they are seen
+type lagsByName struct {
+	lags   []*LAG
+	byName map[string]*LAG
+}
+
+func newLAGs() *lagsByName {
+	return &lagsByName{byName: make(map[string]*LAG)}
+}
+
+func (l *lagsByName) lag(name string) *LAG {
+	lag, found := l.byName[name]
+	if !found {
+		lag = &LAG{Name: name, Active: -1, PartnerSystemIDs: []string{}, Members: []*Member{}}
+		l.byName[name] = lag
+		l.lags = append(l.lags, lag)
+	}
+
+	return lag
+}
+
+func (l *lagsByName) member(lagName, intf string) *Member {
+	lag := l.lag(lagName)
+	for _, m := range lag.Members {
+		if m.Interface == intf {
+			return m
+		}
+	}
+
+	m := &Member{Interface: intf, Active: -1, ActorState: -1, PartnerState: -1}
+	lag.Members = append(lag.Members, m)
+	return m
+}
+
+// result counts the active members and collects the partner system IDs of the LAGs
+func (l *lagsByName) result() []*LAG {
+	lags := []*LAG{}
+	for _, lag := range l.lags {
+		partners := make(map[string]bool)
+		for _, m := range lag.Members {
+			if m.Active >= 0 {
+				if lag.Active < 0 {
+					lag.Active = 0
+				}
+				lag.Active += m.Active
+			}
+
+			if m.PartnerSystemID != "" && !zeroIDRegexp.MatchString(m.PartnerSystemID) && !partners[m.PartnerSystemID] {
+				partners[m.PartnerSystemID] = true
+				lag.PartnerSystemIDs = append(lag.PartnerSystemIDs, m.PartnerSystemID)
+			}
+		}
+		lags = append(lags, lag)
+	}
+
+	return lags
+}
+
+// splitColumns cuts a table row at the start of the columns of the dashed separator line below the header
+func splitColumns(line string, spans [][]int) []string {
+	columns := make([]string, 0, len(spans))
+	for i, span := range spans {
+		start := span[0]
+		if i == 0 {
+			start = 0
+		}
+		if start >= len(line) {
+			columns = append(columns, "")
+			continue
+		}
+
+		end := len(line)
+		if i+1 < len(spans) && spans[i+1][0] < end {
+			end = spans[i+1][0]
+		}
+		columns = append(columns, strings.TrimSpace(strings.Trim(strings.TrimSpace(line[start:end]), "|")))
+	}
+
+	return columns
+}
@@ -0,0 +1,78 @@
+package lag
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*lagCollector)
+	golden.TestParsers(t, c.parsers, "lag")
+}
+
+func TestParseMalformedOutput(t *testing.T) {
+	c := &lagCollector{}
+	actor := "Actor details of all interfaces:\n----------------------------------------------------------------------------------\n"
+	lacp := "        LACP    Trunk   Port            LACP    Admin  Oper\n   Port Enabled Group   Status  Partner Status  Key    Key\n   ---- ------- ------- ------- ------- ------- ------ ------\n"
+	trunks := "  Port   | Name                             Type      | Group  Type\n  ------ + -------------------------------- --------- + ------ --------\n"
+
+	tests := []struct {
+		name   string
+		parse  func(string) ([]*LAG, error)
+		output string
+		want   []*LAG
+	}{
+		{name: "empty output", parse: c.ParseArubaCXLAGs, want: []*LAG{}},
+		{name: "interfaces without aggregate", parse: c.ParseArubaCXLAGs, output: "Interfaces       : 1/1/47 1/1/48\n", want: []*LAG{}},
+		{
+			name:   "aggregate without interfaces",
+			parse:  c.ParseArubaCXLAGs,
+			output: "Aggregate name   : lag3\nInterfaces       :\n",
+			want:   []*LAG{{Name: "lag3", Active: -1, PartnerSystemIDs: []string{}, Members: []*Member{}}},
+		},
+		{
+			name:   "actor row with a missing column",
+			parse:  c.ParseArubaCXLAGs,
+			output: actor + "1/1/47     lag1       48    1     ALFNCD  38:21:c7:5f:a1:c0 65534\n",
+			want:   []*LAG{},
+		},
+		{
+			name:   "actor row with invalid state",
+			parse:  c.ParseArubaCXLAGs,
+			output: actor + "1/1/47     lag1       48    1     n/a     38:21:c7:5f:a1:c0 65534  1    up\n",
+			want:   []*LAG{},
+		},
+		{name: "AOS-S empty output", parse: c.ParseArubaSwitchLAGs, want: []*LAG{}},
+		{name: "AOS-S empty table", parse: c.ParseArubaSwitchLAGs, output: lacp + "\n", want: []*LAG{}},
+		{
+			name:   "AOS-S LACP row without partner column",
+			parse:  c.ParseArubaSwitchLAGs,
+			output: lacp + "   25   Active  Trk1    Up\n",
+			want:   []*LAG{{Name: "Trk1", Active: -1, PartnerSystemIDs: []string{}, Members: []*Member{{Interface: "25", Active: -1, ActorState: -1, PartnerState: -1}}}},
+		},
+		{
+			name:   "AOS-S trunk row without port",
+			parse:  c.ParseArubaSwitchLAGs,
+			output: trunks + "         | uplink-1                         SFP+SR    | Trk1   LACP\n",
+			want:   []*LAG{},
+		},
+	}
+
+	for _, test := range tests {
+		lags, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, lags)
+
+		if !reflect.DeepEqual(lags, test.want) {
+			got, _ := json.Marshal(lags)
+			want, _ := json.Marshal(test.want)
+			t.Errorf("%s: got %s, want %s", test.name, got, want)
+		}
+	}
+}
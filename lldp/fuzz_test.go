@@ -0,0 +1,33 @@
+package lldp
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseNeighbors(f *testing.F) {
+	golden.Seeds(f, "lldp")
+	c := NewCollector().(*lldpCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("neighbors") {
+			parsed, _ := p.Parse(out)
+			golden.CheckValues(t, parsed)
+			neighbors, _ := parsed.([]*Neighbor)
+			NewTopology([]*Device{{Target: "10.0.0.1", Neighbors: neighbors}})
+		}
+	})
+}
+
+func FuzzParseLocalDevice(f *testing.F) {
+	golden.Seeds(f, "lldp")
+	c := NewCollector().(*lldpCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("local") {
+			local, _ := p.Parse(out)
+			golden.CheckValues(t, local)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package lldp
+
+// Neighbor is a device seen by LLDP on a local port
+type Neighbor struct {
+	LocalPort         string
+	ChassisID         string
+	SystemName        string
+	PortID            string
+	PortDescription   string
+	ManagementAddress string
+}
+
+// LocalDevice is how the device announces itself by LLDP
+type LocalDevice struct {
+	ChassisID  string
+	SystemName string
+}
@@ -0,0 +1,117 @@
+package lldp
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_lldp_"
+
+var (
+	NeighborInfoDesc *prometheus.Desc
+	NeighborsDesc    *prometheus.Desc
+)
+
+func init() {
+	collector.Register("lldp", false, NewCollector)
+
+	l := []string{"target"}
+
+	NeighborInfoDesc = collector.NewDesc(prefix+"neighbor_info", "Neighbor seen by LLDP on a local port", append(l, "local_port", "remote_system_name", "remote_port_id", "remote_chassis_id"))
+	NeighborsDesc = collector.NewDesc(prefix+"neighbors", "Number of LLDP neighbors", l)
+}
+
+type lldpCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &lldpCollector{
+		parsers: collector.NewParsers("lldp"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *lldpCollector) registerParsers() {
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show lldp neighbor-info detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchNeighbors(out) },
+	})
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show lldp neighbor-info detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchNeighbors(out) },
+	})
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaInstant,
+		Commands: []string{"show ap lldp neighbors"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaNeighborTable(out) },
+	})
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaController,
+		Commands: []string{"show lldp neighbor"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaNeighborTable(out) },
+	})
+	c.parsers.Register("local", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show lldp local-device"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchLocalDevice(out) },
+	})
+	c.parsers.Register("local", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show lldp info local-device"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchLocalDevice(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *lldpCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*lldpCollector) Name() string {
+	return "LLDP"
+}
+
+func (c *lldpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- NeighborInfoDesc
+	ch <- NeighborsDesc
+}
+
+func (c *lldpCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("neighbors", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "neighbors", client, ch, labelValues)
+	if err != nil || parsed == nil {
+		return err
+	}
+	neighbors := parsed.([]*Neighbor)
+
+	for _, n := range neighbors {
+		ch <- prometheus.MustNewConstMetric(NeighborInfoDesc, prometheus.GaugeValue, 1, append(labelValues, n.LocalPort, n.SystemName, n.PortID, n.ChassisID)...)
+	}
+	ch <- prometheus.MustNewConstMetric(NeighborsDesc, prometheus.GaugeValue, float64(len(neighbors)), labelValues...)
+
+	local := LocalDevice{}
+	if c.parsers.Supports("local", client.OSType) {
+		parsed, err = collector.Run(c.parsers, "local", client, ch, labelValues)
+		if err != nil {
+			return err
+		}
+		if parsed != nil {
+			local = parsed.(LocalDevice)
+		}
+	}
+
+	store(labelValues[0], local, neighbors)
+
+	return nil
+}
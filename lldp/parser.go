@@ -0,0 +1,179 @@
+package lldp
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	fieldRegexp     = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z .-]*?)\s*:\s*(.*?)\s*$`)
+	separatorRegexp = regexp.MustCompile(`^-{3,}[\s-]*$`)
+	dashesRegexp    = regexp.MustCompile(`-+`)
+)
+
+// ParseArubaSwitchNeighbors parses "show lldp neighbor-info detail" of AOS-CX and AOS-S. Both have a
+// "<name> : <value>" section per local port, only the names of the fields differ. A repeated field starts
+// another neighbor on the same port, ports without a chassis ID or system name have no neighbor.
+func (c *lldpCollector) ParseArubaSwitchNeighbors(output string) ([]*Neighbor, error) {
+	neighbors := []*Neighbor{}
+
+	var current *Neighbor
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		m := fieldRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		name, value := strings.ToLower(m[1]), m[2]
+		if name == "port" || name == "local port" {
+			current = &Neighbor{LocalPort: value}
+			neighbors = append(neighbors, current)
+			continue
+		}
+		if current == nil || value == "" {
+			continue
+		}
+
+		var field func(n *Neighbor) *string
+		switch name {
+		case "neighbor chassis-name", "sysname", "system name":
+			field = func(n *Neighbor) *string { return &n.SystemName }
+		case "neighbor chassis-id", "chassisid", "chassis id":
+			field = func(n *Neighbor) *string { return &n.ChassisID }
+		case "neighbor port-id", "portid", "port id":
+			field = func(n *Neighbor) *string { return &n.PortID }
+		case "neighbor port-desc", "portdescr":
+			field = func(n *Neighbor) *string { return &n.PortDescription }
+		case "neighbor management-address", "address":
+			// only the first of several management addresses
+			if current.ManagementAddress == "" {
+				current.ManagementAddress = value
+			}
+			continue
+		default:
+			continue
+		}
+
+		if *field(current) != "" {
+			current = &Neighbor{LocalPort: current.LocalPort}
+			neighbors = append(neighbors, current)
+		}
+		*field(current) = value
+	}
+
+	return present(neighbors), nil
+}
+
+// ParseArubaNeighborTable parses the neighbor tables of "show ap lldp neighbors" (Instant) and "show lldp neighbor"
+// (controller). The columns are found by their header, as both name them differently.
+func (c *lldpCollector) ParseArubaNeighborTable(output string) ([]*Neighbor, error) {
+	neighbors := []*Neighbor{}
+
+	var spans [][]int
+	var names []string
+	var previous string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if separatorRegexp.MatchString(line) && strings.Contains(strings.TrimSpace(line), " ") {
+			spans = dashesRegexp.FindAllStringIndex(line, -1)
+			names = splitColumns(previous, spans)
+			continue
+		}
+		previous = line
+
+		if spans == nil {
+			continue
+		}
+		if len(strings.TrimSpace(line)) == 0 {
+			spans = nil
+			continue
+		}
+
+		n := &Neighbor{}
+		for i, value := range splitColumns(line, spans) {
+			switch strings.ToLower(names[i]) {
+			case "interface", "local intf":
+				n.LocalPort = value
+			case "neighbor", "system name":
+				n.SystemName = value
+			case "chassis-id", "chassis id":
+				n.ChassisID = value
+			case "port-id", "remote intf":
+				n.PortID = value
+			case "port-desc":
+				n.PortDescription = value
+			case "mgmt. address":
+				n.ManagementAddress = value
+			}
+		}
+		if n.LocalPort != "" {
+			neighbors = append(neighbors, n)
+		}
+	}
+
+	return present(neighbors), nil
+}
+
+// ParseArubaSwitchLocalDevice parses "show lldp local-device" (AOS-CX) and "show lldp info local-device" (AOS-S)
+func (c *lldpCollector) ParseArubaSwitchLocalDevice(output string) (LocalDevice, error) {
+	local := LocalDevice{}
+
+	for _, line := range strings.Split(output, "\n") {
+		m := fieldRegexp.FindStringSubmatch(strings.TrimRight(line, "\r "))
+		if m == nil {
+			continue
+		}
+
+		switch strings.ToLower(m[1]) {
+		case "chassis-id", "chassis id":
+			local.ChassisID = m[2]
+		case "system name":
+			local.SystemName = m[2]
+		}
+	}
+
+	if local.ChassisID == "" && local.SystemName == "" {
+		return local, errors.New("no chassis ID or system name found")
+	}
+
+	return local, nil
+}
+
+// present drops the entries of ports without a neighbor
+func present(neighbors []*Neighbor) []*Neighbor {
+	result := []*Neighbor{}
+	for _, n := range neighbors {
+		if n.ChassisID != "" || n.SystemName != "" {
+			result = append(result, n)
+		}
+	}
+
+	return result
+}
+
+// splitColumns cuts a table row at the start of the columns of the dashed separator line below the header
+func splitColumns(line string, spans [][]int) []string {
+	columns := make([]string, 0, len(spans))
+	for i, span := range spans {
+		start := span[0]
+		if i == 0 {
+			start = 0
+		}
+		if start >= len(line) {
+			columns = append(columns, "")
+			continue
+		}
+
+		end := len(line)
+		if i+1 < len(spans) && spans[i+1][0] < end {
+			end = spans[i+1][0]
+		}
+		columns = append(columns, strings.TrimSpace(line[start:end]))
+	}
+
+	return columns
+}
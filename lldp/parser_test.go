@@ -0,0 +1,95 @@
+package lldp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*lldpCollector)
+	golden.TestParsers(t, c.parsers, "lldp")
+}
+
+func TestParseMalformedNeighbors(t *testing.T) {
+	c := &lldpCollector{}
+	table := "Local Intf  Chassis ID         Capability  Remote Intf  Expiry-Time (Secs)  System Name\n----------  ----------         ----------  -----------  ------------------  -----------\n"
+
+	tests := []struct {
+		name   string
+		parse  func(string) ([]*Neighbor, error)
+		output string
+		want   []*Neighbor
+	}{
+		{name: "empty output", parse: c.ParseArubaSwitchNeighbors, want: []*Neighbor{}},
+		{name: "port without neighbor", parse: c.ParseArubaSwitchNeighbors, output: "Port                           : 1/1/1\nNeighbor Entries               : 0\n", want: []*Neighbor{}},
+		{
+			name:   "neighbor without port",
+			parse:  c.ParseArubaSwitchNeighbors,
+			output: "Neighbor Chassis-Name          : access-1\nNeighbor Chassis-ID            : a0:b3:c4:d5:e6:f7\n",
+			want:   []*Neighbor{},
+		},
+		{
+			name:   "fields without values",
+			parse:  c.ParseArubaSwitchNeighbors,
+			output: "Port                           : 1/1/1\nNeighbor Chassis-Name          :\nNeighbor Chassis-ID            : a0:b3:c4:d5:e6:f7\nNeighbor Port-ID               :\n",
+			want:   []*Neighbor{{LocalPort: "1/1/1", ChassisID: "a0:b3:c4:d5:e6:f7"}},
+		},
+		{name: "table empty output", parse: c.ParseArubaNeighborTable, want: []*Neighbor{}},
+		{name: "empty table", parse: c.ParseArubaNeighborTable, output: table + "\nNumber of neighbors: 0\n", want: []*Neighbor{}},
+		{
+			name:   "row without system name column",
+			parse:  c.ParseArubaNeighborTable,
+			output: table + "GE0/0/0     38:21:c7:5f:a1:c0  B:R         1/1/48\n",
+			want:   []*Neighbor{{LocalPort: "GE0/0/0", ChassisID: "38:21:c7:5f:a1:c0", PortID: "1/1/48"}},
+		},
+		{
+			name:   "row without local port",
+			parse:  c.ParseArubaNeighborTable,
+			output: table + "            38:21:c7:5f:a1:c0  B:R         1/1/48       105                 core-1\n",
+			want:   []*Neighbor{},
+		},
+	}
+
+	for _, test := range tests {
+		neighbors, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, neighbors)
+
+		if !reflect.DeepEqual(neighbors, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, neighbors, test.want)
+		}
+	}
+}
+
+func TestParseMalformedLocalDevice(t *testing.T) {
+	c := &lldpCollector{}
+
+	tests := []struct {
+		name   string
+		output string
+		want   LocalDevice
+		err    string
+	}{
+		{name: "empty output", err: "no chassis ID or system name found"},
+		{name: "fields without values", output: "Chassis-id              :\nSystem Name             :\n", err: "no chassis ID or system name found"},
+		{name: "system name only", output: "System Name             : core-1\nTTL                     : 120\n", want: LocalDevice{SystemName: "core-1"}},
+	}
+
+	for _, test := range tests {
+		local, err := c.ParseArubaSwitchLocalDevice(test.output)
+		if test.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+		}
+		if local != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, local, test.want)
+		}
+	}
+}
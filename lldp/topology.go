@@ -0,0 +1,181 @@
+package lldp
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slashdoom/aruba_exporter/util"
+)
+
+// Device is the LLDP data last collected from a target
+type Device struct {
+	Target    string
+	Local     LocalDevice
+	Neighbors []*Neighbor
+	Collected time.Time
+}
+
+// Graph is the topology of the devices and their neighbors
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Links []*Link `json:"links"`
+}
+
+// Node is a device of the topology. Host is set for the devices collected by the exporter.
+type Node struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name,omitempty"`
+	ChassisID         string     `json:"chassis_id,omitempty"`
+	ManagementAddress string     `json:"management_address,omitempty"`
+	Host              string     `json:"host,omitempty"`
+	Collected         *time.Time `json:"collected,omitempty"`
+}
+
+// Link connects a port of the source node with a port of the target node
+type Link struct {
+	Source     string `json:"source"`
+	SourcePort string `json:"source_port"`
+	Target     string `json:"target"`
+	TargetPort string `json:"target_port,omitempty"`
+}
+
+var (
+	collectedMu sync.RWMutex
+	collected   = make(map[string]*Device)
+)
+
+// store keeps the LLDP data of the last scrape of a target
+func store(target string, local LocalDevice, neighbors []*Neighbor) {
+	collectedMu.Lock()
+	defer collectedMu.Unlock()
+
+	collected[target] = &Device{
+		Target:    target,
+		Local:     local,
+		Neighbors: neighbors,
+		Collected: time.Now(),
+	}
+}
+
+// Collected gets the LLDP data last collected from the targets, targets not collected yet are left out
+func Collected(targets []string) []*Device {
+	collectedMu.RLock()
+	defer collectedMu.RUnlock()
+
+	devices := []*Device{}
+	for _, t := range targets {
+		if d, found := collected[t]; found {
+			devices = append(devices, d)
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Target < devices[j].Target })
+
+	return devices
+}
+
+// NewTopology assembles the graph of the devices and their neighbors. A neighbor is the same node as a device
+// if it has the chassis ID of the device or, for devices without local LLDP data, the host as management address.
+// A link seen from both ends is added once.
+func NewTopology(devices []*Device) *Graph {
+	g := &Graph{Nodes: []*Node{}, Links: []*Link{}}
+	nodes := make(map[string]*Node)
+	byChassis := make(map[string]*Node)
+	byAddress := make(map[string]*Node)
+
+	add := func(n *Node) *Node {
+		if existing, found := nodes[n.ID]; found {
+			return existing
+		}
+		nodes[n.ID] = n
+		g.Nodes = append(g.Nodes, n)
+		if n.ChassisID != "" {
+			byChassis[chassisKey(n.ChassisID)] = n
+		}
+		return n
+	}
+
+	for _, d := range devices {
+		id := d.Target
+		if d.Local.ChassisID != "" {
+			id = chassisKey(d.Local.ChassisID)
+		}
+		collected := d.Collected
+		n := add(&Node{
+			ID:        id,
+			Name:      d.Local.SystemName,
+			ChassisID: d.Local.ChassisID,
+			Host:      d.Target,
+			Collected: &collected,
+		})
+		if d.Local.ChassisID == "" {
+			byAddress[d.Target] = n
+		}
+	}
+
+	links := make(map[string]bool)
+	for _, d := range devices {
+		source := byAddress[d.Target]
+		if source == nil {
+			source = byChassis[chassisKey(d.Local.ChassisID)]
+		}
+
+		for _, neighbor := range d.Neighbors {
+			remote := byChassis[chassisKey(neighbor.ChassisID)]
+			if remote == nil {
+				remote = byAddress[neighbor.ManagementAddress]
+			}
+			if remote == nil {
+				id := chassisKey(neighbor.ChassisID)
+				if id == "" {
+					id = neighbor.SystemName
+				}
+				remote = add(&Node{
+					ID:                id,
+					Name:              neighbor.SystemName,
+					ChassisID:         neighbor.ChassisID,
+					ManagementAddress: neighbor.ManagementAddress,
+				})
+			}
+			if remote.Name == "" {
+				remote.Name = neighbor.SystemName
+			}
+
+			key := linkKey(source.ID, neighbor.LocalPort, remote.ID, neighbor.PortID)
+			if links[key] {
+				continue
+			}
+			links[key] = true
+
+			g.Links = append(g.Links, &Link{
+				Source:     source.ID,
+				SourcePort: neighbor.LocalPort,
+				Target:     remote.ID,
+				TargetPort: neighbor.PortID,
+			})
+		}
+	}
+
+	return g
+}
+
+// chassisKey gets the chassis ID in the same format for all OS types if it is a MAC address
+func chassisKey(chassisID string) string {
+	s := strings.ReplaceAll(chassisID, " ", "")
+	if mac := util.StandardizeMacAddr(s); mac != "" {
+		return mac
+	}
+
+	return s
+}
+
+// linkKey is the same for both directions of a link
+func linkKey(a, portA, b, portB string) string {
+	x, y := a+"\x00"+portA, b+"\x00"+portB
+	if x > y {
+		x, y = y, x
+	}
+
+	return x + "\x00" + y
+}
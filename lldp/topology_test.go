@@ -0,0 +1,53 @@
+package lldp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+// TestTopology assembles the graph of the samples, in which the devices see each other
+func TestTopology(t *testing.T) {
+	c := NewCollector().(*lldpCollector)
+	hosts := map[string]string{
+		rpc.ArubaCXSwitch:   "10.0.0.1",
+		rpc.ArubaSwitch:     "10.0.1.2",
+		rpc.ArubaInstant:    "10.0.1.102",
+		rpc.ArubaController: "10.0.0.10",
+	}
+
+	devices := []*Device{}
+	for _, osType := range rpc.OSTypes {
+		d := &Device{Target: hosts[osType], Collected: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+		for _, p := range c.parsers.Registered("neighbors") {
+			if p.OSType != osType {
+				continue
+			}
+			out, _ := golden.Sample(t, "lldp", osType, p.Commands)
+			neighbors, err := p.Parse(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.Neighbors = neighbors.([]*Neighbor)
+		}
+
+		for _, p := range c.parsers.Registered("local") {
+			if p.OSType != osType {
+				continue
+			}
+			out, _ := golden.Sample(t, "lldp", osType, p.Commands)
+			local, err := p.Parse(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.Local = local.(LocalDevice)
+		}
+
+		devices = append(devices, d)
+	}
+
+	golden.Assert(t, "topology", NewTopology(devices), nil)
+}
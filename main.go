@@ -2,17 +2,22 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
-//	"os/signal"
+	"os/signal"
 	"net/http"
-//	"strings"
+	"sync"
+	"syscall"
+	"strings"
 //	"time"
 
 	"github.com/slashdoom/aruba_exporter/config"
 	"github.com/slashdoom/aruba_exporter/connector"
+	"github.com/slashdoom/aruba_exporter/inventory"
+	"github.com/slashdoom/aruba_exporter/lldp"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -22,6 +27,8 @@ import (
 
 const version string = "0.0.1"
 
+const topologyPath string = "/api/v1/topology"
+
 var (
 	showVersion        = flag.Bool("version", false, "Print version information.")
 	listenAddress      = flag.String("web.listen-address", ":9909", "Address on which to expose metrics and web interface.")
@@ -30,24 +37,42 @@ var (
 	sshUsername        = flag.String("ssh.user", "aruba_exporter", "Username to use when connecting to devices using ssh")
 	sshKeyFile         = flag.String("ssh.keyfile", "", "Public key file to use when connecting to devices using ssh")
 	sshPassword        = flag.String("ssh.password", "", "Password to use when connecting to devices using ssh")
+	sshPasswordFile    = flag.String("ssh.password-file", "", "File containing the password to use when connecting to devices using ssh")
+	sshPasswordCommand = flag.String("ssh.password-command", "", "Command whose output is the password to use when connecting to devices using ssh")
 	sshTimeout         = flag.Int("ssh.timeout", 5, "Timeout to use for SSH connection")
 	sshBatchSize       = flag.Int("ssh.batch-size", 10000, "The SSH response batch size")
+	sshCommandDelay    = flag.Int("ssh.command-delay", 1000, "Milliseconds to wait after sending each command")
 	level              = flag.String("level", "info", "Set logging verbose level")
 	configFile         = flag.String("config.file", "", "Path to config file")
+	configCheck        = flag.Bool("config.check", false, "Check the config file for problems and exit")
+	featureFlags       = featureEnabledFlags()
 	devices            []*connector.Device
+	deviceCounts       map[string]int
+	staticDevices      []*connector.Device
+	discoverer         *inventory.Discoverer
 	cfg                *config.Config
+	cfgMu              sync.RWMutex
 )
 
 func init() {
 	log.SetOutput(os.Stdout)
 	flag.Usage = func() {
-		fmt.Println("Usage: aruba_exporter [ ... ]\n\nParameters:")
+		fmt.Println("Usage: aruba_exporter [ ... ]")
+		fmt.Println("       aruba_exporter parse -collector <name> -os <OS type> -file <file> (run aruba_exporter parse -h for details)")
+		fmt.Println("       aruba_exporter anonymize [-mapping <file>] [-output <directory>] <file or directory> ... (run aruba_exporter anonymize -h for details)\n\nParameters:")
 		fmt.Println()
 		flag.PrintDefaults()
 	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "parse" {
+		os.Exit(runParse(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "anonymize" {
+		os.Exit(runAnonymize(os.Args[2:], os.Stdout))
+	}
+
 	flag.Parse()
 
 	if *showVersion {
@@ -55,11 +80,17 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *configCheck {
+		os.Exit(checkConfig())
+	}
+
 	err := initialize()
 	if err != nil {
 		log.Fatalf("could not initialize exporter. %v", err)
 	}
 
+	go reloadOnSignal()
+
 	startServer()
 }
 
@@ -73,15 +104,101 @@ func initialize() error {
 		log.SetLevel(l)
 	}
 
-	devices, err = devicesForConfig(c)
+	d, err := devicesForConfig(c)
+	if err != nil {
+		return err
+	}
+
+	err = validateLabelKeys(d, c)
 	if err != nil {
 		return err
 	}
+
+	var disc *inventory.Discoverer
+	if c.Inventory != nil {
+		disc = inventory.NewDiscoverer(c.Inventory)
+		disc.Refresh()
+	}
+
+	cfgMu.Lock()
+	if discoverer != nil {
+		discoverer.Stop()
+	}
+	staticDevices = d
+	discoverer = disc
 	cfg = c
+	cfgMu.Unlock()
+
+	updateDevices()
+	if disc != nil {
+		go disc.Run(updateDevices)
+	}
 
 	return nil
 }
 
+// updateDevices merges the devices from the config with the discovered ones
+func updateDevices() {
+	cfgMu.Lock()
+	defer cfgMu.Unlock()
+
+	discovered := map[string][]*config.DeviceConfig{}
+	if discoverer != nil {
+		discovered = discoverer.Devices()
+	}
+
+	devices, deviceCounts = devicesForInventory(cfg, staticDevices, discovered)
+	log.Infof("Active devices: %d", len(devices))
+}
+
+func reloadOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		log.Infoln("Reloading config")
+		err := initialize()
+		if err != nil {
+			log.Errorf("could not reload config, keeping the previous one. %v", err)
+		}
+	}
+}
+
+func checkConfig() int {
+	if len(*configFile) == 0 {
+		fmt.Println("-config.check requires -config.file")
+		return 2
+	}
+
+	f, err := os.Open(*configFile)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	defer f.Close()
+
+	problems, err := config.Check(f)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	for _, p := range problems {
+		if p.Line > 0 {
+			fmt.Printf("%s:%d: %s\n", *configFile, p.Line, p.Message)
+		} else {
+			fmt.Printf("%s: %s\n", *configFile, p.Message)
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Printf("%d problem(s) found\n", len(problems))
+		return 1
+	}
+
+	fmt.Printf("%s: OK\n", *configFile)
+	return 0
+}
+
 func printVersion() {
 	fmt.Println("aruba_exporter")
 	fmt.Printf("Version: %s\n", version)
@@ -97,7 +214,7 @@ func loadConfig() (*config.Config, error) {
 
 	if len(*configFile) == 0 {
 		log.Infoln("Loading config flags")
-		return loadConfigFromFlags(), nil
+		return loadConfigFromFlags()
 	}
 
 	log.Infoln("Loading config from", *configFile)
@@ -106,25 +223,64 @@ func loadConfig() (*config.Config, error) {
 		return nil, err
 	}
 
-	return config.Load(bytes.NewReader(b))
+	c, err := config.Load(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	applyFeatureFlags(c)
+
+	return c, nil
+}
+
+// featureEnabledFlags creates a -<name>.enabled flag for every registered collector
+func featureEnabledFlags() map[string]*bool {
+	flags := make(map[string]*bool)
+	for _, name := range config.Features() {
+		flags[name] = flag.Bool(name+".enabled", config.FeatureDefault(name), "Enable the "+name+" collector")
+	}
+
+	return flags
+}
+
+// applyFeatureFlags overrides the global features of the config with the -<name>.enabled flags set on the command line
+func applyFeatureFlags(c *config.Config) {
+	if c.Features == nil {
+		c.Features = config.FeatureConfig{}
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		name := strings.TrimSuffix(f.Name, ".enabled")
+		if enabled, found := featureFlags[name]; found && name != f.Name {
+			c.Features[name] = *enabled
+		}
+	})
 }
 
-func loadConfigFromFlags() *config.Config {
+func loadConfigFromFlags() (*config.Config, error) {
 	c := config.New()
 
 	c.Level = *level
 	c.Timeout = *sshTimeout
 	c.BatchSize = *sshBatchSize
+	c.CommandDelay = *sshCommandDelay
 	c.Username = *sshUsername
-	c.Password = *sshPassword
+	c.Password = config.Secret(*sshPassword)
+	c.PasswordFile = *sshPasswordFile
+	c.PasswordCommand = *sshPasswordCommand
 	c.KeyFile = *sshKeyFile
 	c.DevicesFromTargets(*sshHosts)
+
+	err := c.ResolveSecrets()
+	if err != nil {
+		return nil, err
+	}
+	applyFeatureFlags(c)
 	log.Debugln(c)
 
 	f := c.Features
 	log.Debugln(f)
 
-	return c
+	return c, nil
 }
 
 func startServer() {
@@ -138,6 +294,7 @@ func startServer() {
   <body>
     <h1>Aruba Exporter</h1>
     <p><a href="` + *metricsPath + `">Metrics</a></p>
+    <p><a href="` + topologyPath + `">Topology</a> (LLDP neighbors of the last scrape)</p>
     <h2>More information:</h2>
     <p><a href="https://github.com/slashdoom/aruba_exporter">github.com/slashdoom/aruba_exporter</a></p>
   </body>
@@ -145,18 +302,52 @@ func startServer() {
 `))
 	})
 	http.HandleFunc(*metricsPath, handleMetricsRequest)
+	http.HandleFunc(topologyPath, handleTopologyRequest)
 
 	log.Infof("Listening for %s on %s\n", *metricsPath, *listenAddress)
 	log.Fatal(http.ListenAndServe(*listenAddress, nil))
 }
 
 func handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
-	reg := prometheus.NewRegistry()
+	cfgMu.RLock()
+	h := metricsHandler(devices, deviceCounts, cfg)
+	cfgMu.RUnlock()
 
-	a := newArubaCollector(devices)
-	reg.MustRegister(a)
+	h.ServeHTTP(w, r)
+}
+
+// metricsHandler creates a handler which scrapes the devices
+func metricsHandler(devices []*connector.Device, counts map[string]int, c *config.Config) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(newInventoryCollector(counts))
+	reg.MustRegister(newArubaCollector(devices, c))
 
-	promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{
 		ErrorLog:      log.New(),
-		ErrorHandling: promhttp.ContinueOnError}).ServeHTTP(w, r)
+		ErrorHandling: promhttp.ContinueOnError})
+}
+
+func handleTopologyRequest(w http.ResponseWriter, r *http.Request) {
+	cfgMu.RLock()
+	h := topologyHandler(devices)
+	cfgMu.RUnlock()
+
+	h.ServeHTTP(w, r)
+}
+
+// topologyHandler creates a handler which serves the graph of the LLDP neighbors last collected from the devices
+func topologyHandler(devices []*connector.Device) http.Handler {
+	targets := make([]string, len(devices))
+	for i, d := range devices {
+		targets[i] = d.DeviceConfig.Host
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		err := json.NewEncoder(w).Encode(lldp.NewTopology(lldp.Collected(targets)))
+		if err != nil {
+			log.Errorf("could not write topology. %v", err)
+		}
+	})
 }
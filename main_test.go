@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/config"
+	"github.com/slashdoom/aruba_exporter/fakedevice"
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/lldp"
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+// scrape starts a fake device and gets the metrics of a scrape of it.
+// The durations are removed, as they differ between scrapes.
+func scrape(t *testing.T, osType string, f fakedevice.Faults, setup ...func(*config.Config)) string {
+	t.Helper()
+
+	lines := []string{}
+	for _, l := range strings.SplitAfter(scrapeWithDurations(t, osType, f, setup...), "\n") {
+		if strings.Contains(l, "duration_seconds{") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+
+	return strings.Join(lines, "")
+}
+
+// scrapeWithDurations starts a fake device and gets all metrics of a scrape of it
+func scrapeWithDurations(t *testing.T, osType string, f fakedevice.Faults, setup ...func(*config.Config)) string {
+	t.Helper()
+
+	s := fakedevice.New(osType)
+	err := s.LoadSamples("samples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetFaults(f)
+	err = s.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	host, port := s.HostPort()
+	p, _ := strconv.Atoi(port)
+	c := config.New()
+	c.Timeout = 3
+	c.CommandDelay = 0
+	c.Username = s.Username
+	c.Password = config.Secret(s.Password)
+	c.Devices = []*config.DeviceConfig{{Host: host, Port: &p}}
+	for _, name := range config.Features() {
+		c.Features[name] = true
+	}
+	for _, fn := range setup {
+		fn(c)
+	}
+
+	devs, err := devicesForConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	metricsHandler(devs, map[string]int{configSource: len(devs)}, c).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	for _, osType := range rpc.OSTypes {
+		osType := osType
+		t.Run(osType, func(t *testing.T) {
+			t.Parallel()
+
+			golden.AssertText(t, "metrics/"+osType, scrape(t, osType, fakedevice.Faults{}))
+		})
+	}
+}
+
+func TestMetricsAuthFailure(t *testing.T) {
+	t.Parallel()
+
+	out := scrape(t, rpc.ArubaCXSwitch, fakedevice.Faults{FailAuth: true})
+	if !strings.Contains(out, `aruba_up{target="127.0.0.1"} 0`) {
+		t.Errorf("target is not down after an authentication failure:\n%s", out)
+	}
+}
+
+func TestMetricsDisconnect(t *testing.T) {
+	t.Parallel()
+
+	// the session is closed after show version, so no collector gets any output
+	out := scrape(t, rpc.ArubaCXSwitch, fakedevice.Faults{DisconnectAfter: 3})
+	if !strings.Contains(out, `aruba_up{target="127.0.0.1"} 1`) {
+		t.Errorf("target is not up:\n%s", out)
+	}
+	if strings.Contains(out, "aruba_system_") {
+		t.Errorf("got system metrics after the session was closed:\n%s", out)
+	}
+}
+
+func TestTranscripts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	scrape(t, rpc.ArubaCXSwitch, fakedevice.Faults{}, func(c *config.Config) {
+		c.Transcripts = &config.TranscriptsConfig{Directory: dir}
+		record := true
+		c.Devices[0].RecordTranscripts = &record
+	})
+
+	for _, path := range []string{"identify/unknown/show version", "system/ArubaCXSwitch/show version", "system/ArubaCXSwitch/top memory", "interfaces/ArubaCXSwitch/show interface", "connect/unknown/no page"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "127.0.0.1", path))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(string(b), filepath.Base(path)) {
+			t.Errorf("%s does not contain the command:\n%s", path, b)
+		}
+	}
+
+	sample, err := ioutil.ReadFile("samples/interface/ArubaCXSwitch/show interface")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "127.0.0.1", "interfaces", rpc.ArubaCXSwitch, "show interface"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), strings.TrimSpace(strings.ReplaceAll(string(sample), "\r", ""))) {
+		t.Errorf("transcript does not contain the output of the command:\n%s", b)
+	}
+}
+
+func TestTopology(t *testing.T) {
+	scrape(t, rpc.ArubaCXSwitch, fakedevice.Faults{})
+
+	c := config.New()
+	c.Password = "secret"
+	c.Devices = []*config.DeviceConfig{{Host: "127.0.0.1"}, {Host: "192.0.2.1"}}
+	devs, err := devicesForConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	topologyHandler(devs).ServeHTTP(w, httptest.NewRequest("GET", topologyPath, nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q", ct)
+	}
+
+	g := &lldp.Graph{}
+	err = json.Unmarshal(w.Body.Bytes(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []string{}
+	for _, n := range g.Nodes {
+		if n.Host != "" {
+			hosts = append(hosts, n.Host)
+		}
+	}
+	if len(hosts) != 1 || hosts[0] != "127.0.0.1" {
+		t.Errorf("got nodes of the hosts %v, want only the scraped one", hosts)
+	}
+	if len(g.Links) == 0 {
+		t.Errorf("got no links:\n%s", w.Body.String())
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		err := ioutil.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name   string
+		file   string
+		status int
+		output string
+	}{
+		{name: "no file", file: "", status: 2, output: "-config.check requires -config.file\n"},
+		{name: "missing file", file: filepath.Join(dir, "missing.yml"), status: 2, output: "open " + filepath.Join(dir, "missing.yml") + ": no such file or directory\n"},
+		{name: "valid", file: write("valid.yml", "timeout: 5\ndevices:\n  - host: switch1\n"), status: 0, output: filepath.Join(dir, "valid.yml") + ": OK\n"},
+		{
+			name:   "problems",
+			file:   write("invalid.yml", "timout: 5\ndevices:\n  - host: switch1\n  - host: switch1\n"),
+			status: 1,
+			output: filepath.Join(dir, "invalid.yml") + ":1: unknown field \"timout\"\n" +
+				filepath.Join(dir, "invalid.yml") + ":4: duplicate host \"switch1\", first defined at line 3\n" +
+				"2 problem(s) found\n",
+		},
+	}
+
+	previous := *configFile
+	defer func() { *configFile = previous }()
+
+	for _, test := range tests {
+		*configFile = test.file
+
+		var status int
+		output := captureStdout(t, func() { status = checkConfig() })
+		if status != test.status {
+			t.Errorf("%s: got exit status %d, want %d", test.name, status, test.status)
+		}
+		if output != test.output {
+			t.Errorf("%s: got output %q, want %q", test.name, output, test.output)
+		}
+	}
+}
+
+// captureStdout gets what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
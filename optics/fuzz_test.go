@@ -0,0 +1,19 @@
+package optics
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseTransceivers(f *testing.F) {
+	golden.Seeds(f, "optics")
+	c := NewCollector().(*opticsCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("transceivers") {
+			items, _ := p.Parse(out)
+			golden.CheckValues(t, items, "Temperature", "TxPower", "RxPower")
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package optics
+
+// Transceiver is a module in a port with its digital optical monitoring (DOM) readings.
+// Readings are nil if the module does not support DOM.
+type Transceiver struct {
+	Port         string
+	Type         string
+	Vendor       string
+	PartNumber   string
+	SerialNumber string
+
+	Temperature *Reading
+	Voltage     *Reading
+	Bias        *Reading
+	TxPower     *Reading
+	RxPower     *Reading
+}
+
+// Reading is a measured value and its alarm and warning thresholds by level (e.g. high_alarm).
+// Value is nil if the module reports no value (e.g. N/A for the power of a disabled laser).
+type Reading struct {
+	Value      *float64
+	Thresholds map[string]float64
+}
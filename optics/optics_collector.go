@@ -0,0 +1,133 @@
+package optics
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_optics_"
+
+var (
+	InfoDesc                 *prometheus.Desc
+	TemperatureDesc          *prometheus.Desc
+	TemperatureThresholdDesc *prometheus.Desc
+	VoltageDesc              *prometheus.Desc
+	VoltageThresholdDesc     *prometheus.Desc
+	BiasDesc                 *prometheus.Desc
+	BiasThresholdDesc        *prometheus.Desc
+	TxPowerDesc              *prometheus.Desc
+	TxPowerThresholdDesc     *prometheus.Desc
+	RxPowerDesc              *prometheus.Desc
+	RxPowerThresholdDesc     *prometheus.Desc
+)
+
+func init() {
+	collector.Register("optics", false, NewCollector)
+
+	l := []string{"target", "port"}
+	li := []string{"target", "port", "type", "vendor", "part_number", "serial_number"}
+	lt := []string{"target", "port", "level"}
+
+	InfoDesc = collector.NewDesc(prefix+"info", "Transceiver module in the port", li)
+
+	TemperatureDesc = collector.NewDesc(prefix+"temperature_celsius", "Temperature of the transceiver in Celsius", l)
+	TemperatureThresholdDesc = collector.NewDesc(prefix+"temperature_threshold_celsius", "Temperature alarm and warning thresholds of the transceiver in Celsius", lt)
+	VoltageDesc = collector.NewDesc(prefix+"voltage_volts", "Supply voltage of the transceiver in volts", l)
+	VoltageThresholdDesc = collector.NewDesc(prefix+"voltage_threshold_volts", "Supply voltage alarm and warning thresholds of the transceiver in volts", lt)
+	BiasDesc = collector.NewDesc(prefix+"bias_milliamperes", "Laser bias current of the transceiver in mA", l)
+	BiasThresholdDesc = collector.NewDesc(prefix+"bias_threshold_milliamperes", "Laser bias current alarm and warning thresholds of the transceiver in mA", lt)
+	TxPowerDesc = collector.NewDesc(prefix+"tx_power_dbm", "Transmit power of the transceiver in dBm", l)
+	TxPowerThresholdDesc = collector.NewDesc(prefix+"tx_power_threshold_dbm", "Transmit power alarm and warning thresholds of the transceiver in dBm", lt)
+	RxPowerDesc = collector.NewDesc(prefix+"rx_power_dbm", "Receive power of the transceiver in dBm", l)
+	RxPowerThresholdDesc = collector.NewDesc(prefix+"rx_power_threshold_dbm", "Receive power alarm and warning thresholds of the transceiver in dBm", lt)
+}
+
+type opticsCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &opticsCollector{
+		parsers: collector.NewParsers("optics"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *opticsCollector) registerParsers() {
+	c.parsers.Register("transceivers", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show interfaces transceiver detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchTransceivers(out) },
+	})
+	c.parsers.Register("transceivers", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show interface transceiver detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchTransceivers(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *opticsCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*opticsCollector) Name() string {
+	return "Optics"
+}
+
+func (c *opticsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- InfoDesc
+	ch <- TemperatureDesc
+	ch <- TemperatureThresholdDesc
+	ch <- VoltageDesc
+	ch <- VoltageThresholdDesc
+	ch <- BiasDesc
+	ch <- BiasThresholdDesc
+	ch <- TxPowerDesc
+	ch <- TxPowerThresholdDesc
+	ch <- RxPowerDesc
+	ch <- RxPowerThresholdDesc
+}
+
+func (c *opticsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("transceivers", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "transceivers", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+
+	for port, t := range parsed.(map[string]*Transceiver) {
+		l := append(labelValues, port)
+
+		ch <- prometheus.MustNewConstMetric(InfoDesc, prometheus.GaugeValue, 1, append(l, t.Type, t.Vendor, t.PartNumber, t.SerialNumber)...)
+
+		collectReading(ch, t.Temperature, TemperatureDesc, TemperatureThresholdDesc, l)
+		collectReading(ch, t.Voltage, VoltageDesc, VoltageThresholdDesc, l)
+		collectReading(ch, t.Bias, BiasDesc, BiasThresholdDesc, l)
+		collectReading(ch, t.TxPower, TxPowerDesc, TxPowerThresholdDesc, l)
+		collectReading(ch, t.RxPower, RxPowerDesc, RxPowerThresholdDesc, l)
+	}
+
+	return nil
+}
+
+func collectReading(ch chan<- prometheus.Metric, r *Reading, desc, thresholdDesc *prometheus.Desc, labelValues []string) {
+	if r == nil {
+		return
+	}
+
+	if r.Value != nil {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, *r.Value, labelValues...)
+	}
+	for level, v := range r.Thresholds {
+		ch <- prometheus.MustNewConstMetric(thresholdDesc, prometheus.GaugeValue, v, append(labelValues, level)...)
+	}
+}
@@ -0,0 +1,169 @@
+package optics
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	transceiverRegexp = regexp.MustCompile(`^\s*Transceiver (?:in|on) (\S+)`)
+	fieldRegexp       = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z ]*?)\s*:\s*(.*?)\s*$`)
+	thresholdRegexp   = regexp.MustCompile(`^\s*(Temperature|Voltage|Tx Bias|Bias|Tx Power|Rx Power)\s+(\S.*)$`)
+	valueRegexp       = regexp.MustCompile(`(-?\d+(?:\.\d+)?)\s*(dBm|mW|mA|C|V)\b`)
+	levelRegexp       = regexp.MustCompile(`(?i)(high|low)\s+(alarm|warn)`)
+)
+
+// ParseArubaSwitchTransceivers parses the transceiver details of AOS-S and AOS-CX, which share the same layout:
+// a block per port starting with "Transceiver in <port>", the module fields, the status with the current
+// readings and a table of the alarm and warning thresholds.
+func (c *opticsCollector) ParseArubaSwitchTransceivers(output string) (map[string]*Transceiver, error) {
+	transceivers := make(map[string]*Transceiver)
+
+	var current *Transceiver
+	var levels []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := transceiverRegexp.FindStringSubmatch(line); m != nil {
+			current = &Transceiver{Port: m[1]}
+			transceivers[current.Port] = current
+			levels = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if l := thresholdLevels(line); l != nil {
+			levels = l
+			continue
+		}
+
+		if m := fieldRegexp.FindStringSubmatch(line); m != nil {
+			setField(current, m[1], m[2])
+			continue
+		}
+
+		if m := thresholdRegexp.FindStringSubmatch(line); m != nil && levels != nil {
+			values := values(m[2])
+			if len(values) != len(levels) {
+				log.Tracef("skipping thresholds: %s", line)
+				continue
+			}
+			r := reading(current, m[1])
+			for i, level := range levels {
+				r.Thresholds[level] = values[i]
+			}
+		}
+	}
+
+	return transceivers, nil
+}
+
+// thresholdLevels gets the levels of the threshold columns from a table header, in the order of the columns
+func thresholdLevels(line string) []string {
+	matches := levelRegexp.FindAllStringSubmatchIndex(line, -1)
+	if len(matches) < 4 {
+		return nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i][0] < matches[j][0] })
+	levels := make([]string, len(matches))
+	for i, m := range matches {
+		kind := strings.ToLower(line[m[4]:m[5]])
+		if kind == "warn" {
+			kind = "warning"
+		}
+		levels[i] = strings.ToLower(line[m[2]:m[3]]) + "_" + kind
+	}
+
+	return levels
+}
+
+func setField(t *Transceiver, key, value string) {
+	switch key {
+	case "Type":
+		t.Type = value
+	case "Vendor Name", "Vendor":
+		t.Vendor = value
+	case "Vendor Part Number", "Model", "Product Number":
+		t.PartNumber = value
+	case "Part Number":
+		if t.PartNumber == "" {
+			t.PartNumber = value
+		}
+	case "Vendor Serial Number", "Serial Number":
+		t.SerialNumber = value
+	case "Temperature", "Voltage", "Tx Bias", "Bias", "Tx Power", "Rx Power":
+		values := values(value)
+		if len(values) == 0 {
+			log.Tracef("no value for %s of %s: %s", key, t.Port, value)
+			reading(t, key)
+			return
+		}
+		v := values[0]
+		reading(t, key).Value = &v
+	}
+}
+
+// reading gets the reading of a measurement, it is created if the transceiver does not have it yet
+func reading(t *Transceiver, measurement string) *Reading {
+	var r **Reading
+	switch measurement {
+	case "Temperature":
+		r = &t.Temperature
+	case "Voltage":
+		r = &t.Voltage
+	case "Tx Bias", "Bias":
+		r = &t.Bias
+	case "Tx Power":
+		r = &t.TxPower
+	default:
+		r = &t.RxPower
+	}
+
+	if *r == nil {
+		*r = &Reading{Thresholds: make(map[string]float64)}
+	}
+
+	return *r
+}
+
+// values gets the numbers with a unit in a text. Powers are returned in dBm, if a power
+// is given in mW and dBm only the dBm values are used, mW values are converted otherwise.
+func values(s string) []float64 {
+	matches := valueRegexp.FindAllStringSubmatch(s, -1)
+
+	dBm := false
+	for _, m := range matches {
+		if m[2] == "dBm" {
+			dBm = true
+		}
+	}
+
+	values := []float64{}
+	for _, m := range matches {
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case m[2] == "mW" && dBm:
+			continue
+		case m[2] == "mW":
+			if v <= 0 {
+				continue
+			}
+			v = math.Round(10*math.Log10(v)*1000) / 1000
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
@@ -0,0 +1,86 @@
+package optics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*opticsCollector)
+	golden.TestParsers(t, c.parsers, "optics")
+}
+
+func TestParseMalformedTransceivers(t *testing.T) {
+	c := &opticsCollector{}
+	header := "Transceiver in 1/1/1\n   Type : SFP+SR\n"
+	thresholds := "   Alarm/Warning thresholds:\n                 High Alarm      Low Alarm       High Warning    Low Warning\n"
+	value := 36.57
+
+	tests := []struct {
+		name         string
+		output       string
+		transceivers int
+		reading      bool
+		value        *float64
+		thresholds   int
+	}{
+		{name: "empty output"},
+		{name: "fields without a transceiver", output: "   Type : SFP+SR\n   Temperature : 36.57C\n"},
+		{name: "no readings", output: header, transceivers: 1},
+		{name: "non-numeric reading", output: header + "     Temperature : N/A\n", transceivers: 1, reading: true},
+		{name: "reading without unit", output: header + "     Temperature : 36.57\n", transceivers: 1, reading: true},
+		{
+			name:         "thresholds",
+			output:       header + "     Temperature : 36.57C\n" + thresholds + "   Temperature   75.00C          -5.00C          70.00C          0.00C\n",
+			transceivers: 1, reading: true, value: &value, thresholds: 4,
+		},
+		{
+			name:         "threshold row with a missing column",
+			output:       header + "     Temperature : 36.57C\n" + thresholds + "   Temperature   75.00C          -5.00C          70.00C\n",
+			transceivers: 1, reading: true, value: &value,
+		},
+		{
+			name:         "threshold row with a non-numeric column",
+			output:       header + "     Temperature : 36.57C\n" + thresholds + "   Temperature   75.00C          -5.00C          70.00C          N/A\n",
+			transceivers: 1, reading: true, value: &value,
+		},
+		{
+			name:         "threshold rows without a header",
+			output:       header + "     Temperature : 36.57C\n   Temperature   75.00C          -5.00C          70.00C          0.00C\n",
+			transceivers: 1, reading: true, value: &value,
+		},
+	}
+
+	for _, test := range tests {
+		transceivers, err := c.ParseArubaSwitchTransceivers(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, transceivers, "Temperature", "TxPower", "RxPower")
+		if len(transceivers) != test.transceivers {
+			t.Errorf("%s: got %d transceivers, want %d", test.name, len(transceivers), test.transceivers)
+			continue
+		}
+		if test.transceivers == 0 {
+			continue
+		}
+
+		r := transceivers["1/1/1"].Temperature
+		if (r != nil) != test.reading {
+			t.Errorf("%s: got temperature %v, want a reading: %v", test.name, r, test.reading)
+			continue
+		}
+		if r == nil {
+			continue
+		}
+		if !reflect.DeepEqual(r.Value, test.value) {
+			t.Errorf("%s: got temperature %v, want %v", test.name, r.Value, test.value)
+		}
+		if len(r.Thresholds) != test.thresholds {
+			t.Errorf("%s: got thresholds %v, want %d", test.name, r.Thresholds, test.thresholds)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ospf
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseNeighbors(f *testing.F) {
+	golden.Seeds(f, "ospf")
+	c := NewCollector().(*ospfCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("neighbors") {
+			neighbors, _ := p.Parse(out)
+			golden.CheckValues(t, neighbors)
+		}
+	})
+}
+
+func FuzzParseInterfaces(f *testing.F) {
+	golden.Seeds(f, "ospf")
+	c := NewCollector().(*ospfCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("interfaces") {
+			interfaces, _ := p.Parse(out)
+			golden.CheckValues(t, interfaces)
+		}
+	})
+}
+
+func FuzzParseLSAs(f *testing.F) {
+	golden.Seeds(f, "ospf")
+	c := NewCollector().(*ospfCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("lsas") {
+			areas, _ := p.Parse(out)
+			golden.CheckValues(t, areas)
+		}
+	})
+}
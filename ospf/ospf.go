@@ -0,0 +1,30 @@
+package ospf
+
+// Neighbor is an OSPF neighbor. Role is the designated router role of the neighbor (DR, BDR or DROTHER).
+type Neighbor struct {
+	VRF       string
+	RouterID  string
+	Address   string
+	Interface string
+	State     string
+	Role      string
+	Priority  float64
+}
+
+// Interface is an interface OSPF is enabled on. Network is the subnet of the interface (e.g. 10.0.0.0/24)
+// if the output contains it, it is used to find the interface of a neighbor by its address.
+type Interface struct {
+	VRF     string
+	Name    string
+	Address string
+	Network string
+	Area    string
+	State   string
+}
+
+// AreaLSAs is the number of LSAs per type in the link state database of an area
+type AreaLSAs struct {
+	VRF  string
+	Area string
+	LSAs map[string]float64
+}
@@ -0,0 +1,155 @@
+package ospf
+
+import (
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_ospf_"
+
+var (
+	NeighborStateDesc *prometheus.Desc
+	NeighborsDesc     *prometheus.Desc
+	LSAsDesc          *prometheus.Desc
+
+	// states are the values of the neighbor state metric, as ospfNbrState of the OSPF-MIB
+	states = map[string]float64{
+		"DOWN":     1,
+		"ATTEMPT":  2,
+		"INIT":     3,
+		"2WAY":     4,
+		"EXSTART":  5,
+		"EXCHANGE": 6,
+		"LOADING":  7,
+		"FULL":     8,
+	}
+)
+
+func init() {
+	collector.Register("ospf", false, NewCollector)
+
+	NeighborStateDesc = collector.NewDesc(prefix+"neighbor_state", "State of the neighbor: 1 = down, 2 = attempt, 3 = init, 4 = 2way, 5 = exstart, 6 = exchange, 7 = loading, 8 = full, 0 = unknown", []string{"target", "vrf", "area", "interface", "neighbor_id", "neighbor_address"})
+	NeighborsDesc = collector.NewDesc(prefix+"neighbors", "Number of neighbors on the interface", []string{"target", "vrf", "area", "interface"})
+	LSAsDesc = collector.NewDesc(prefix+"lsas", "Number of LSAs in the link state database of the area", []string{"target", "vrf", "area", "type"})
+}
+
+type ospfCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &ospfCollector{
+		parsers: collector.NewParsers("ospf"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *ospfCollector) registerParsers() {
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show ip ospf neighbors all-vrfs"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXNeighbors(out) },
+	})
+	c.parsers.Register("neighbors", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show ip ospf neighbor"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchNeighbors(out) },
+	})
+	c.parsers.Register("interfaces", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show ip ospf interface brief all-vrfs"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXInterfaces(out) },
+	})
+	c.parsers.Register("interfaces", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show ip ospf interface", "show ip"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchInterfaces(out) },
+	})
+	c.parsers.Register("lsas", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show ip ospf lsdb all-vrfs"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXLSAs(out) },
+	})
+	c.parsers.Register("lsas", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show ip ospf link-state"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchLSAs(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *ospfCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*ospfCollector) Name() string {
+	return "OSPF"
+}
+
+func (c *ospfCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- NeighborStateDesc
+	ch <- NeighborsDesc
+	ch <- LSAsDesc
+}
+
+func (c *ospfCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("neighbors", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "interfaces", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	interfaces, _ := parsed.(map[string]*Interface)
+
+	parsed, err = collector.Run(c.parsers, "neighbors", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	neighbors, _ := parsed.(map[string]*Neighbor)
+
+	type key struct{ vrf, area, name string }
+	counts := make(map[key]float64)
+	for _, i := range interfaces {
+		counts[key{i.VRF, i.Area, i.Name}] = 0
+	}
+
+	for _, n := range neighbors {
+		area := ""
+		name := n.Interface
+		if i := interfaceForNeighbor(interfaces, n); i != nil {
+			area = i.Area
+			name = i.Name
+		}
+		counts[key{n.VRF, area, name}]++
+
+		l := append(labelValues, n.VRF, area, name, n.RouterID, n.Address)
+		ch <- prometheus.MustNewConstMetric(NeighborStateDesc, prometheus.GaugeValue, states[strings.ToUpper(n.State)], l...)
+	}
+
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(NeighborsDesc, prometheus.GaugeValue, count, append(labelValues, k.vrf, k.area, k.name)...)
+	}
+
+	parsed, err = collector.Run(c.parsers, "lsas", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	areas, _ := parsed.(map[string]*AreaLSAs)
+
+	for _, a := range areas {
+		for lsaType, count := range a.LSAs {
+			ch <- prometheus.MustNewConstMetric(LSAsDesc, prometheus.GaugeValue, count, append(labelValues, a.VRF, a.Area, lsaType)...)
+		}
+	}
+
+	return nil
+}
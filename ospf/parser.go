@@ -0,0 +1,267 @@
+package ospf
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/util"
+)
+
+const defaultVRF = "default"
+
+var (
+	vrfRegexp = regexp.MustCompile(`^\s*VRF\s*:\s*(\S+)`)
+
+	cxNeighborRegexp  = regexp.MustCompile(`^(\d+\.\d+\.\d+\.\d+)\s+(\d+)\s+([A-Za-z0-9-]+)(?:/([A-Za-z-]+))?\s+(\d+\.\d+\.\d+\.\d+)\s+(\S+)`)
+	cxInterfaceRegexp = regexp.MustCompile(`^(\S+)\s+(\d+\.\d+\.\d+\.\d+)\s+(\d+\.\d+\.\d+\.\d+)/(\d+)\s+\d+\s+(\S+)`)
+	cxAreaRegexp      = regexp.MustCompile(`^\s*LSDB for Area (\S+)`)
+	cxLSATypeRegexp   = regexp.MustCompile(`^\s*(.+?) Link State Advertisements\s*$`)
+	lsaRegexp         = regexp.MustCompile(`^\s*\d+\.\d+\.\d+\.\d+\s+\d+\.\d+\.\d+\.\d+\s+\d+\s+0x`)
+
+	switchNeighborRegexp  = regexp.MustCompile(`^\s*(\d+\.\d+\.\d+\.\d+)\s+(\d+)\s+(\d+\.\d+\.\d+\.\d+)\s+(\S+)\s+(\S+)`)
+	switchInterfaceRegexp = regexp.MustCompile(`^\s*(\d+\.\d+\.\d+\.\d+)\s+(?:enabled|disabled)\s+(\S+)\s+(\S+)`)
+	switchVLANRegexp      = regexp.MustCompile(`^\s*(.+?)\s*\|\s*\S+\s+(\d+\.\d+\.\d+\.\d+)\s+(\d+\.\d+\.\d+\.\d+)`)
+	switchAreaRegexp      = regexp.MustCompile(`^\s*OSPF Link State Database for Area (\S+)`)
+	switchLSARegexp       = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z-]*(?: [A-Za-z-]+)?)\s+\d+\.\d+\.\d+\.\d+\s+\d+\.\d+\.\d+\.\d+\s+\d+\s+0x`)
+)
+
+// ParseArubaCXNeighbors parses "show ip ospf neighbors all-vrfs", the state column contains the state and the role (e.g. FULL/DR)
+func (c *ospfCollector) ParseArubaCXNeighbors(output string) (map[string]*Neighbor, error) {
+	neighbors := make(map[string]*Neighbor)
+
+	vrf := defaultVRF
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := vrfRegexp.FindStringSubmatch(line); m != nil {
+			vrf = m[1]
+			continue
+		}
+
+		m := cxNeighborRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n := &Neighbor{
+			VRF:       vrf,
+			RouterID:  m[1],
+			Priority:  util.Str2float64(m[2]),
+			State:     strings.ToUpper(m[3]),
+			Role:      strings.ToUpper(m[4]),
+			Address:   m[5],
+			Interface: m[6],
+		}
+		neighbors[vrf+"/"+n.Interface+"/"+n.RouterID] = n
+	}
+
+	return neighbors, nil
+}
+
+// ParseArubaCXInterfaces parses "show ip ospf interface brief all-vrfs"
+func (c *ospfCollector) ParseArubaCXInterfaces(output string) (map[string]*Interface, error) {
+	interfaces := make(map[string]*Interface)
+
+	vrf := defaultVRF
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := vrfRegexp.FindStringSubmatch(line); m != nil {
+			vrf = m[1]
+			continue
+		}
+
+		m := cxInterfaceRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		i := &Interface{
+			VRF:     vrf,
+			Name:    m[1],
+			Area:    m[2],
+			Address: m[3],
+			Network: network(m[3], m[4]),
+			State:   m[5],
+		}
+		interfaces[vrf+"/"+i.Name] = i
+	}
+
+	return interfaces, nil
+}
+
+// ParseArubaCXLSAs parses "show ip ospf lsdb all-vrfs", a table per area and LSA type
+func (c *ospfCollector) ParseArubaCXLSAs(output string) (map[string]*AreaLSAs, error) {
+	areas := make(map[string]*AreaLSAs)
+
+	vrf := defaultVRF
+	var area *AreaLSAs
+	lsaType := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := vrfRegexp.FindStringSubmatch(line); m != nil {
+			vrf = m[1]
+			area = nil
+			continue
+		}
+		if m := cxAreaRegexp.FindStringSubmatch(line); m != nil {
+			area = areaLSAs(areas, vrf, m[1])
+			lsaType = ""
+			continue
+		}
+		if m := cxLSATypeRegexp.FindStringSubmatch(line); m != nil {
+			lsaType = lsaTypeName(m[1])
+			continue
+		}
+
+		if area != nil && lsaType != "" && lsaRegexp.MatchString(line) {
+			area.LSAs[lsaType]++
+		}
+	}
+
+	return areas, nil
+}
+
+// ParseArubaSwitchNeighbors parses "show ip ospf neighbor" of AOS-S, which has no interface column.
+// The interface is found by the address of the neighbor with the interfaces parsed by ParseArubaSwitchInterfaces.
+func (c *ospfCollector) ParseArubaSwitchNeighbors(output string) (map[string]*Neighbor, error) {
+	neighbors := make(map[string]*Neighbor)
+
+	for _, line := range strings.Split(output, "\n") {
+		m := switchNeighborRegexp.FindStringSubmatch(strings.TrimRight(line, "\r "))
+		if m == nil {
+			continue
+		}
+		n := &Neighbor{
+			VRF:      defaultVRF,
+			RouterID: m[1],
+			Priority: util.Str2float64(m[2]),
+			Address:  m[3],
+			Role:     strings.ToUpper(m[4]),
+			State:    strings.ToUpper(m[5]),
+		}
+		neighbors[n.Address+"/"+n.RouterID] = n
+	}
+
+	return neighbors, nil
+}
+
+// ParseArubaSwitchInterfaces parses "show ip ospf interface" and "show ip" of AOS-S. The OSPF interfaces are
+// identified by their address, the VLAN name and subnet are taken from the IP configuration of the VLANs.
+func (c *ospfCollector) ParseArubaSwitchInterfaces(output string) (map[string]*Interface, error) {
+	interfaces := make(map[string]*Interface)
+
+	type vlan struct{ name, network string }
+	vlans := make(map[string]vlan)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := switchVLANRegexp.FindStringSubmatch(line); m != nil {
+			v := vlan{name: m[1]}
+			if ones, size := net.IPMask(net.ParseIP(m[3]).To4()).Size(); size != 0 {
+				v.network = network(m[2], strconv.Itoa(ones))
+			}
+			vlans[m[2]] = v
+			continue
+		}
+
+		m := switchInterfaceRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		area := m[2]
+		if area == "backbone" {
+			area = "0.0.0.0"
+		}
+		interfaces[m[1]] = &Interface{
+			VRF:     defaultVRF,
+			Name:    m[1],
+			Address: m[1],
+			Area:    area,
+			State:   m[3],
+		}
+	}
+
+	for address, i := range interfaces {
+		if v, found := vlans[address]; found {
+			i.Name = v.name
+			i.Network = v.network
+		}
+	}
+
+	return interfaces, nil
+}
+
+// ParseArubaSwitchLSAs parses "show ip ospf link-state" of AOS-S, a table per area with the type of each LSA
+func (c *ospfCollector) ParseArubaSwitchLSAs(output string) (map[string]*AreaLSAs, error) {
+	areas := make(map[string]*AreaLSAs)
+
+	var area *AreaLSAs
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := switchAreaRegexp.FindStringSubmatch(line); m != nil {
+			area = areaLSAs(areas, defaultVRF, m[1])
+			continue
+		}
+
+		if m := switchLSARegexp.FindStringSubmatch(line); m != nil && area != nil {
+			area.LSAs[lsaTypeName(m[1])]++
+		}
+	}
+
+	return areas, nil
+}
+
+// areaLSAs gets the LSAs of an area, they are created if the area was not found yet
+func areaLSAs(areas map[string]*AreaLSAs, vrf, area string) *AreaLSAs {
+	a, found := areas[vrf+"/"+area]
+	if !found {
+		a = &AreaLSAs{
+			VRF:  vrf,
+			Area: area,
+			LSAs: make(map[string]float64),
+		}
+		areas[vrf+"/"+area] = a
+	}
+
+	return a
+}
+
+// lsaTypeName converts the LSA type of the outputs to a label value, e.g. "Inter-area Summary" to inter_area_summary
+func lsaTypeName(s string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(s)))
+}
+
+// network gets the subnet of an address with a prefix length, e.g. 10.0.0.0/24 for 10.0.0.1 and 24
+func network(address, length string) string {
+	_, n, err := net.ParseCIDR(address + "/" + length)
+	if err != nil {
+		return ""
+	}
+
+	return n.String()
+}
+
+// interfaceForNeighbor finds the interface of a neighbor by its name, or if the neighbor
+// has no interface, the interface whose subnet contains the address of the neighbor
+func interfaceForNeighbor(interfaces map[string]*Interface, n *Neighbor) *Interface {
+	ip := net.ParseIP(n.Address)
+	for _, i := range interfaces {
+		if i.VRF != n.VRF {
+			continue
+		}
+		if n.Interface != "" {
+			if i.Name == n.Interface {
+				return i
+			}
+			continue
+		}
+		if _, subnet, err := net.ParseCIDR(i.Network); err == nil && ip != nil && subnet.Contains(ip) {
+			return i
+		}
+	}
+
+	return nil
+}
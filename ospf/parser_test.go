@@ -0,0 +1,107 @@
+package ospf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*ospfCollector)
+	golden.TestParsers(t, c.parsers, "ospf")
+}
+
+func TestParseMalformedOutput(t *testing.T) {
+	c := &ospfCollector{}
+	cxNeighbors := func(out string) (interface{}, error) { return c.ParseArubaCXNeighbors(out) }
+	cxInterfaces := func(out string) (interface{}, error) { return c.ParseArubaCXInterfaces(out) }
+	cxLSAs := func(out string) (interface{}, error) { return c.ParseArubaCXLSAs(out) }
+	switchNeighbors := func(out string) (interface{}, error) { return c.ParseArubaSwitchNeighbors(out) }
+	switchInterfaces := func(out string) (interface{}, error) { return c.ParseArubaSwitchInterfaces(out) }
+	switchLSAs := func(out string) (interface{}, error) { return c.ParseArubaSwitchLSAs(out) }
+
+	tests := []struct {
+		name   string
+		parse  func(string) (interface{}, error)
+		output string
+		want   interface{}
+	}{
+		{name: "neighbors empty output", parse: cxNeighbors, want: map[string]*Neighbor{}},
+		{
+			name:   "neighbors empty table",
+			parse:  cxNeighbors,
+			output: "VRF : default                          Process : 1\n\nTotal Number of Neighbors : 0\n\nNeighbor ID      Priority  State             Nbr Address       Interface\n",
+			want:   map[string]*Neighbor{},
+		},
+		{
+			name:   "neighbor without interface column",
+			parse:  cxNeighbors,
+			output: "10.255.0.2       1         FULL/DR           10.255.0.0\n",
+			want:   map[string]*Neighbor{},
+		},
+		{
+			name:   "neighbor with non-numeric priority",
+			parse:  cxNeighbors,
+			output: "10.255.0.2       n/a       FULL/DR           10.255.0.0        vlan100\n",
+			want:   map[string]*Neighbor{},
+		},
+		{name: "interfaces empty output", parse: cxInterfaces, want: map[string]*Interface{}},
+		{
+			name:   "interface without prefix length",
+			parse:  cxInterfaces,
+			output: "vlan100          0.0.0.0          10.255.0.1         1     BDR          up\n",
+			want:   map[string]*Interface{},
+		},
+		{name: "LSAs empty output", parse: cxLSAs, want: map[string]*AreaLSAs{}},
+		{
+			name:   "LSAs without area",
+			parse:  cxLSAs,
+			output: "Router Link State Advertisements\n10.255.0.1       10.255.0.1       540    0x80000005   0x0000a2b3  3\n",
+			want:   map[string]*AreaLSAs{},
+		},
+		{
+			name:   "area without LSAs",
+			parse:  cxLSAs,
+			output: "LSDB for Area 0.0.0.0\n=====================\n\nRouter Link State Advertisements\n",
+			want:   map[string]*AreaLSAs{"default/0.0.0.0": {VRF: "default", Area: "0.0.0.0", LSAs: map[string]float64{}}},
+		},
+		{
+			name:   "AOS-S neighbor without state column",
+			parse:  switchNeighbors,
+			output: "  10.0.0.2        1   10.0.0.2        DR\n",
+			want:   map[string]*Neighbor{},
+		},
+		{
+			name:   "AOS-S interface without area column",
+			parse:  switchInterfaces,
+			output: "  10.0.0.1        enabled\n",
+			want:   map[string]*Interface{},
+		},
+		{
+			name:   "AOS-S VLAN with invalid subnet mask",
+			parse:  switchInterfaces,
+			output: "  DEFAULT_VLAN       | Manual     10.0.0.1        255.0.255.0       No  No\n  10.0.0.1        enabled  backbone        BDR     none      1      1   no\n",
+			want:   map[string]*Interface{"10.0.0.1": {VRF: "default", Name: "DEFAULT_VLAN", Address: "10.0.0.1", Area: "0.0.0.0", State: "BDR"}},
+		},
+		{
+			name:   "AOS-S LSAs without area",
+			parse:  switchLSAs,
+			output: "  Router      10.0.0.1        10.0.0.1        120  0x80000010  0x0000a1b2\n",
+			want:   map[string]*AreaLSAs{},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, got)
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
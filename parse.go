@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
+)
+
+// parseResult is the result of one parser of a collector
+type parseResult struct {
+	Result interface{} `json:"result"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// savedOutput answers commands with outputs saved to a file or to a directory with a file per command
+type savedOutput struct {
+	path      string
+	output    string
+	byCommand map[string]string
+}
+
+// RunCommand implements rpc.Connection
+func (s *savedOutput) RunCommand(cmds []string) (string, error) {
+	if s.byCommand == nil {
+		return s.output, nil
+	}
+
+	outputs := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		out, found := s.byCommand[cmd]
+		if !found {
+			return "", errors.Errorf("no output for command %q in %s", cmd, s.path)
+		}
+		outputs[i] = out
+	}
+
+	return strings.Join(outputs, "\n"), nil
+}
+
+func readSavedOutput(path string) (*savedOutput, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &savedOutput{path: path}
+	if !info.IsDir() {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		s.output = strings.ReplaceAll(string(b), "\r", "")
+		return s, nil
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	s.byCommand = make(map[string]string)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(path, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s.byCommand[f.Name()] = strings.ReplaceAll(string(b), "\r", "")
+	}
+
+	return s, nil
+}
+
+// offlineCollector runs a collector on saved outputs when the metrics are gathered
+type offlineCollector struct {
+	col    collector.RPCCollector
+	client *rpc.Client
+	target string
+}
+
+// Describe implements prometheus.Collector interface. No descriptors are sent, so the
+// collector is unchecked and the unsupported metric can be reported as well.
+func (c *offlineCollector) Describe(ch chan<- *prometheus.Desc) {
+}
+
+// Collect implements prometheus.Collector interface
+func (c *offlineCollector) Collect(ch chan<- prometheus.Metric) {
+	err := c.col.Collect(c.client, ch, []string{c.target})
+	if err != nil {
+		log.Errorln(c.col.Name() + ": " + err.Error())
+	}
+}
+
+// runParse implements the parse subcommand. It runs the parsers of a collector on saved command outputs
+// and writes the parsed results as JSON followed by the metrics in the Prometheus text format.
+func runParse(args []string, w io.Writer) int {
+	fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+	fs.SetOutput(w)
+	name := fs.String("collector", "", "Name of the collector: "+strings.Join(collector.Names(), ", "))
+	osType := fs.String("os", "", "OS type of the device: "+strings.Join(rpc.OSTypes, ", "))
+	file := fs.String("file", "", "File with the command output, or a directory with a file named after each command (e.g. samples/interface/ArubaCXSwitch)")
+	firmware := fs.String("firmware", "", "Firmware version of the device (e.g. 10.10.0002), used to pick version specific parsers")
+	fs.Usage = func() {
+		fmt.Fprintln(w, "Usage: aruba_exporter parse -collector <name> -os <OS type> -file <file or directory> [-firmware <version>]\n\nParameters:")
+		fmt.Fprintln(w)
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	col, found := collector.New(*name)
+	if !found {
+		fmt.Fprintf(w, "unknown collector %q, must be one of %s\n", *name, strings.Join(collector.Names(), ", "))
+		return 2
+	}
+	if !rpc.IsSupportedOSType(*osType) {
+		fmt.Fprintf(w, "unsupported OS type %q, must be one of %s\n", *osType, strings.Join(rpc.OSTypes, ", "))
+		return 2
+	}
+	if len(*file) == 0 {
+		fmt.Fprintln(w, "parse requires -file")
+		return 2
+	}
+
+	conn, err := readSavedOutput(*file)
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 2
+	}
+
+	// warnings of the parsers must not end up in the output
+	log.SetOutput(os.Stderr)
+
+	client := rpc.NewClientForConnection(conn, *file, *level)
+	client.OSType = *osType
+	client.Version = *firmware
+
+	results := make(map[string]parseResult)
+	if pc, ok := col.(collector.ParserCollector); ok {
+		parsers := pc.Parsers()
+		for _, n := range parsers.Names() {
+			if !parsers.Supports(n, *osType) {
+				continue
+			}
+			results[n] = runParser(parsers, n, client)
+		}
+	}
+
+	b, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 1
+	}
+	fmt.Fprintf(w, "%s\n\n", b)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&offlineCollector{col: col, client: client, target: *file})
+	mfs, err := reg.Gather()
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 1
+	}
+	for _, mf := range mfs {
+		_, err = expfmt.MetricFamilyToText(w, mf)
+		if err != nil {
+			fmt.Fprintln(w, err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func runParser(parsers *collector.Parsers, name string, client *rpc.Client) parseResult {
+	p, found := parsers.Resolve(name, client.OSType, client.Version)
+	if !found {
+		return parseResult{Error: fmt.Sprintf("no parser for %s %s", client.OSType, client.Version)}
+	}
+
+	out, err := client.RunCommand(p.Commands)
+	if err != nil {
+		return parseResult{Error: err.Error()}
+	}
+
+	v, err := p.Parse(out)
+	if err != nil {
+		return parseResult{Result: v, Error: err.Error()}
+	}
+
+	return parseResult{Result: v}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		collector string
+		osType    string
+		dir       string
+	}{
+		{collector: "interfaces", osType: rpc.ArubaCXSwitch, dir: "interface"},
+		{collector: "system", osType: rpc.ArubaController, dir: "system"},
+		{collector: "wireless", osType: rpc.ArubaInstant, dir: "wireless"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.collector+"/"+test.osType, func(t *testing.T) {
+			var b bytes.Buffer
+			code := runParse([]string{"-collector", test.collector, "-os", test.osType, "-file", "samples/" + test.dir + "/" + test.osType}, &b)
+			if code != 0 {
+				t.Fatalf("exit code %d: %s", code, b.String())
+			}
+
+			golden.AssertText(t, "parse/"+test.collector+"/"+test.osType, b.String())
+		})
+	}
+}
+
+func TestParseInvalidArguments(t *testing.T) {
+	tests := [][]string{
+		{"-collector", "unknown", "-os", rpc.ArubaCXSwitch, "-file", "samples"},
+		{"-collector", "system", "-os", "Unknown", "-file", "samples"},
+		{"-collector", "system", "-os", rpc.ArubaCXSwitch},
+		{"-collector", "system", "-os", rpc.ArubaCXSwitch, "-file", "samples/missing"},
+	}
+
+	for _, args := range tests {
+		var b bytes.Buffer
+		if code := runParse(args, &b); code != 2 {
+			t.Errorf("%v: got exit code %d, want 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package poe
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParsePoE(f *testing.F) {
+	golden.Seeds(f, "poe")
+	c := NewCollector().(*poeCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("poe") {
+			poe, _ := p.Parse(out)
+			golden.CheckValues(t, poe)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package poe
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/util"
+)
+
+var (
+	fieldRegexp     = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z ]*?)\s*:\s*(\S.*?)\s*$`)
+	memberRegexp    = regexp.MustCompile(`(?i)^\s*(?:member (\d+) power-over-ethernet:|chassis power-over-ethernet:|system power status for member (\d+))\s*$`)
+	separatorRegexp = regexp.MustCompile(`^[\s-]*-{3,}[\s-]*$`)
+	dashesRegexp    = regexp.MustCompile(`-+`)
+	numberRegexp    = regexp.MustCompile(`^\d+(?:\.\d+)?`)
+)
+
+// ParseArubaSwitchPoE parses "show power-over-ethernet all" and "show power-over-ethernet brief" of AOS-S
+// and "show power-over-ethernet" of AOS-CX. The budget is in a section per member, the ports in a table whose
+// columns are found by their (AOS-S: two line) header.
+func (c *poeCollector) ParseArubaSwitchPoE(output string) (PoE, error) {
+	poe := PoE{}
+
+	var member *Member
+	var spans [][]int
+	var names []string
+	header := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if separatorRegexp.MatchString(line) {
+			spans = dashesRegexp.FindAllStringIndex(line, -1)
+			names = make([]string, len(spans))
+			for _, h := range header {
+				for i, name := range splitColumns(h, spans) {
+					names[i] = strings.TrimSpace(names[i] + " " + name)
+				}
+			}
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			spans = nil
+			header = header[:0]
+			continue
+		}
+
+		if spans != nil {
+			if port := portFromColumns(names, splitColumns(line, spans)); port != nil {
+				poe.Ports = append(poe.Ports, port)
+			}
+			continue
+		}
+		header = append(header, line)
+
+		if m := memberRegexp.FindStringSubmatch(line); m != nil {
+			member = &Member{Member: m[1] + m[2], Budget: -1, Used: -1}
+			if member.Member == "" {
+				member.Member = "1"
+			}
+			poe.Members = append(poe.Members, member)
+			continue
+		}
+
+		m := fieldRegexp.FindStringSubmatch(line)
+		if member == nil || m == nil {
+			continue
+		}
+
+		switch strings.ToLower(m[1]) {
+		case "total available power", "poe power capacity":
+			member.Budget = watts(m[2])
+		case "total used power", "poe power drawn":
+			member.Used = watts(m[2])
+		}
+	}
+
+	return poe, nil
+}
+
+// portFromColumns creates a port from a row of the port table, the names are the headers of the columns
+func portFromColumns(names []string, columns []string) *Port {
+	port := &Port{Class: -1, Allocated: -1, Drawn: -1}
+	class := map[string]string{}
+
+	for i, value := range columns {
+		switch strings.ToLower(names[i]) {
+		case "port", "poe port", "interface":
+			port.Port = value
+		case "status", "detection status":
+			port.Status = strings.ToLower(value)
+		case "priority", "power priority":
+			port.Priority = strings.ToLower(value)
+		case "power class", "pd class", "oper class":
+			class[strings.ToLower(names[i])] = value
+		case "alloc power", "alloc power(w)":
+			port.Allocated = watts(value)
+		case "actual power", "drawn power(w)":
+			port.Drawn = watts(value)
+		}
+	}
+
+	for _, name := range []string{"oper class", "pd class", "power class"} {
+		if v, found := class[name]; found && numberRegexp.MatchString(v) {
+			port.Class = util.Str2float64(v)
+			break
+		}
+	}
+
+	if port.Port == "" || port.Status == "" {
+		return nil
+	}
+
+	return port
+}
+
+// watts gets the value of a power like "17 W", "6.8 W" or "24 W +/- 6W", it is -1 if there is none
+func watts(s string) float64 {
+	n := numberRegexp.FindString(strings.TrimSpace(s))
+	if n == "" {
+		return -1
+	}
+
+	return util.Str2float64(n)
+}
+
+// splitColumns cuts a table row at the start of the columns of the dashed separator line below the header
+func splitColumns(line string, spans [][]int) []string {
+	columns := make([]string, 0, len(spans))
+	for i, span := range spans {
+		start := span[0]
+		if i == 0 {
+			start = 0
+		}
+		if start >= len(line) {
+			columns = append(columns, "")
+			continue
+		}
+
+		end := len(line)
+		if i+1 < len(spans) && spans[i+1][0] < end {
+			end = spans[i+1][0]
+		}
+		columns = append(columns, strings.TrimSpace(line[start:end]))
+	}
+
+	return columns
+}
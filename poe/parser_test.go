@@ -0,0 +1,73 @@
+package poe
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*poeCollector)
+	golden.TestParsers(t, c.parsers, "poe")
+}
+
+func TestParseMalformedOutput(t *testing.T) {
+	c := &poeCollector{}
+	table := "Port          Status        Priority  PD Class  Oper Class  Alloc Power(W)  Drawn Power(W)  PD Type\n------------- ------------- --------- --------- ----------- --------------- --------------- -------\n"
+
+	tests := []struct {
+		name   string
+		output string
+		want   PoE
+	}{
+		{name: "empty output"},
+		{name: "empty port table", output: table + "\n"},
+		{
+			name:   "rows without a header",
+			output: "1/1/1         delivering    low       4         4           25.50           6.80            Type-2\n",
+		},
+		{
+			name:   "row without status column",
+			output: table + "1/1/1\n",
+		},
+		{
+			name:   "row without power columns",
+			output: table + "1/1/1         delivering    low\n",
+			want:   PoE{Ports: []*Port{{Port: "1/1/1", Status: "delivering", Priority: "low", Class: -1, Allocated: -1, Drawn: -1}}},
+		},
+		{
+			name:   "non-numeric values",
+			output: table + "1/1/1         delivering    low       n/a       n/a         n/a             -6.80           Type-2\n",
+			want:   PoE{Ports: []*Port{{Port: "1/1/1", Status: "delivering", Priority: "low", Class: -1, Allocated: -1, Drawn: -1}}},
+		},
+		{
+			name:   "member without budget",
+			output: "System Power Status for member 1\n  PoE Power Capacity            : N/A\n",
+			want:   PoE{Members: []*Member{{Member: "1", Budget: -1, Used: -1}}},
+		},
+		{
+			name:   "budget without member",
+			output: "  PoE Power Capacity            : 370.00 W\n  PoE Power Drawn               : 21.60 W\n",
+		},
+	}
+
+	for _, test := range tests {
+		poe, err := c.ParseArubaSwitchPoE(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, poe)
+
+		if !reflect.DeepEqual(poe, test.want) {
+			t.Errorf("%s: got %s, want %s", test.name, format(poe), format(test.want))
+		}
+	}
+}
+
+func format(p PoE) string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
@@ -0,0 +1,24 @@
+package poe
+
+// PoE is the power over ethernet budget of the members and the power of the ports
+type PoE struct {
+	Members []*Member
+	Ports   []*Port
+}
+
+// Member is the PoE budget of a stack member (member 1 if the switch is not stacked). Values are in watts.
+type Member struct {
+	Member string
+	Budget float64
+	Used   float64
+}
+
+// Port is the PoE state of a port. Values are in watts, Class is -1 if no device was classified.
+type Port struct {
+	Port      string
+	Status    string
+	Priority  string
+	Class     float64
+	Allocated float64
+	Drawn     float64
+}
@@ -0,0 +1,135 @@
+package poe
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_poe_"
+
+// portStatuses are reported for every port, so that a change of the status is visible as a change of the value
+var portStatuses = []string{"delivering", "searching", "fault", "denied", "disabled"}
+
+var (
+	MemberBudgetDesc  *prometheus.Desc
+	MemberUsedDesc    *prometheus.Desc
+	PortDrawnDesc     *prometheus.Desc
+	PortAllocatedDesc *prometheus.Desc
+	PortClassDesc     *prometheus.Desc
+	PortStatusDesc    *prometheus.Desc
+)
+
+func init() {
+	collector.Register("poe", false, NewCollector)
+
+	l := []string{"target", "member"}
+	MemberBudgetDesc = collector.NewDesc(prefix+"member_power_budget_watts", "PoE power available to the ports of the member", l)
+	MemberUsedDesc = collector.NewDesc(prefix+"member_power_used_watts", "PoE power used by the ports of the member", l)
+
+	l = []string{"target", "port"}
+	PortDrawnDesc = collector.NewDesc(prefix+"port_power_drawn_watts", "Power drawn by the powered device on the port", l)
+	PortAllocatedDesc = collector.NewDesc(prefix+"port_power_allocated_watts", "Power allocated to the port", l)
+	PortClassDesc = collector.NewDesc(prefix+"port_class", "PoE class of the powered device on the port", l)
+	PortStatusDesc = collector.NewDesc(prefix+"port_status", "PoE status of the port (delivering, searching, fault, denied, disabled), 1 for the current status", append(l, "status"))
+}
+
+type poeCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &poeCollector{
+		parsers: collector.NewParsers("poe"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *poeCollector) registerParsers() {
+	c.parsers.Register("poe", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show power-over-ethernet all", "show power-over-ethernet brief"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchPoE(out) },
+	})
+	c.parsers.Register("poe", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show power-over-ethernet"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchPoE(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *poeCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*poeCollector) Name() string {
+	return "PoE"
+}
+
+func (c *poeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- MemberBudgetDesc
+	ch <- MemberUsedDesc
+	ch <- PortDrawnDesc
+	ch <- PortAllocatedDesc
+	ch <- PortClassDesc
+	ch <- PortStatusDesc
+}
+
+func (c *poeCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("poe", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "poe", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	poe := parsed.(PoE)
+
+	for _, m := range poe.Members {
+		l := append(labelValues, m.Member)
+		if m.Budget >= 0 {
+			ch <- prometheus.MustNewConstMetric(MemberBudgetDesc, prometheus.GaugeValue, m.Budget, l...)
+		}
+		if m.Used >= 0 {
+			ch <- prometheus.MustNewConstMetric(MemberUsedDesc, prometheus.GaugeValue, m.Used, l...)
+		}
+	}
+
+	for _, port := range poe.Ports {
+		l := append(labelValues, port.Port)
+		if port.Drawn >= 0 {
+			ch <- prometheus.MustNewConstMetric(PortDrawnDesc, prometheus.GaugeValue, port.Drawn, l...)
+		}
+		if port.Allocated >= 0 {
+			ch <- prometheus.MustNewConstMetric(PortAllocatedDesc, prometheus.GaugeValue, port.Allocated, l...)
+		}
+		if port.Class >= 0 {
+			ch <- prometheus.MustNewConstMetric(PortClassDesc, prometheus.GaugeValue, port.Class, l...)
+		}
+
+		known := false
+		for _, status := range portStatuses {
+			known = known || status == port.Status
+			ch <- prometheus.MustNewConstMetric(PortStatusDesc, prometheus.GaugeValue, bool2float64(status == port.Status), append(l, status)...)
+		}
+		if !known {
+			ch <- prometheus.MustNewConstMetric(PortStatusDesc, prometheus.GaugeValue, 1, append(l, port.Status)...)
+		}
+	}
+
+	return nil
+}
+
+func bool2float64(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseRoutes(f *testing.F) {
+	golden.Seeds(f, "routes")
+	c := NewCollector().(*routesCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("routes") {
+			vrfs, _ := p.Parse(out)
+			golden.CheckValues(t, vrfs)
+		}
+	})
+}
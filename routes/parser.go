@@ -0,0 +1,158 @@
+package routes
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultVRF = "default"
+
+var (
+	cxVRFRegexp        = regexp.MustCompile(`^\s*VRF\s*:\s*(\S+)`)
+	cxRouteRegexp      = regexp.MustCompile(`^(\d+\.\d+\.\d+\.\d+/\d+)\s+.*?\s([A-Z]+)(?:/[A-Z0-9]+)?\s+\[\d+/\d+\]`)
+	controllerRegexp   = regexp.MustCompile(`^([A-Za-z])\S*\s+(\d+\.\d+\.\d+\.\d+/\d+)\s`)
+	switchRouteRegexp  = regexp.MustCompile(`^\s*(\d+\.\d+\.\d+\.\d+/\d+)\s+\S+\s+(?:\d+\s+)?([a-z]+)\b`)
+	instantRouteRegexp = regexp.MustCompile(`^(default|\d+\.\d+\.\d+\.\d+)\s+(\S+)\s+(\d+\.\d+\.\d+\.\d+)\s+[A-Z!]+\s`)
+
+	// protocols maps the origin codes of AOS-CX and controllers to protocol names
+	protocols = map[string]string{
+		"B": "bgp",
+		"C": "connected",
+		"I": "ike-overlay",
+		"L": "local",
+		"M": "mgmt",
+		"O": "ospf",
+		"R": "rip",
+		"S": "static",
+		"V": "vpn",
+	}
+)
+
+// ParseArubaCXRoutes parses "show ip route all-vrfs", a table per VRF with the origin code of each route.
+// Additional next hops of a route are on lines without a prefix.
+func (c *routesCollector) ParseArubaCXRoutes(output string) (map[string]*VRFRoutes, error) {
+	vrfs := make(map[string]*VRFRoutes)
+
+	vrf := defaultVRF
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := cxVRFRegexp.FindStringSubmatch(line); m != nil {
+			vrf = m[1]
+			routes(vrfs, vrf)
+			continue
+		}
+
+		m := cxRouteRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		add(routes(vrfs, vrf), m[1], protocol(m[2]))
+	}
+
+	return vrfs, nil
+}
+
+// ParseArubaSwitchRoutes parses "show ip route" of AOS-S, which has no VRFs. The type column is the protocol.
+func (c *routesCollector) ParseArubaSwitchRoutes(output string) (map[string]*VRFRoutes, error) {
+	vrfs := make(map[string]*VRFRoutes)
+
+	for _, line := range strings.Split(output, "\n") {
+		m := switchRouteRegexp.FindStringSubmatch(strings.TrimRight(line, "\r "))
+		if m == nil {
+			continue
+		}
+		add(routes(vrfs, defaultVRF), m[1], m[2])
+	}
+
+	return vrfs, nil
+}
+
+// ParseArubaControllerRoutes parses "show ip route" of controllers and gateways. Each route starts with its
+// origin code, followed by "*" for the candidate default. Additional next hops are on lines without a prefix.
+func (c *routesCollector) ParseArubaControllerRoutes(output string) (map[string]*VRFRoutes, error) {
+	vrfs := make(map[string]*VRFRoutes)
+
+	for _, line := range strings.Split(output, "\n") {
+		m := controllerRegexp.FindStringSubmatch(strings.TrimRight(line, "\r "))
+		if m == nil {
+			continue
+		}
+		add(routes(vrfs, defaultVRF), m[2], protocol(m[1]))
+	}
+
+	return vrfs, nil
+}
+
+// ParseArubaInstantRoutes parses "show ip route" of Instant APs, the kernel routing table.
+// Routes with a gateway are counted as static, all others as connected.
+func (c *routesCollector) ParseArubaInstantRoutes(output string) (map[string]*VRFRoutes, error) {
+	vrfs := make(map[string]*VRFRoutes)
+
+	for _, line := range strings.Split(output, "\n") {
+		m := instantRouteRegexp.FindStringSubmatch(strings.TrimRight(line, "\r "))
+		if m == nil {
+			continue
+		}
+
+		destination := m[1]
+		if destination == "default" {
+			destination = "0.0.0.0"
+		}
+		mask := net.ParseIP(m[3]).To4()
+		if mask == nil {
+			continue
+		}
+		bits, size := net.IPMask(mask).Size()
+		if size == 0 {
+			// not a contiguous netmask
+			continue
+		}
+
+		proto := "static"
+		if m[2] == "0.0.0.0" || m[2] == "*" {
+			proto = "connected"
+		}
+		add(routes(vrfs, defaultVRF), destination+"/"+strconv.Itoa(bits), proto)
+	}
+
+	return vrfs, nil
+}
+
+// routes gets the routes of a VRF, they are created if the VRF was not found yet
+func routes(vrfs map[string]*VRFRoutes, vrf string) *VRFRoutes {
+	r, found := vrfs[vrf]
+	if !found {
+		r = &VRFRoutes{
+			VRF:      vrf,
+			Routes:   make(map[string]float64),
+			prefixes: make(map[string]bool),
+		}
+		vrfs[vrf] = r
+	}
+
+	return r
+}
+
+// add counts a route, a prefix is only counted once per VRF
+func add(r *VRFRoutes, prefix, protocol string) {
+	if r.prefixes[prefix] {
+		return
+	}
+	r.prefixes[prefix] = true
+
+	r.Routes[protocol]++
+	if prefix == "0.0.0.0/0" {
+		r.DefaultRoute = true
+	}
+}
+
+func protocol(code string) string {
+	if p, found := protocols[strings.ToUpper(code)]; found {
+		return p
+	}
+
+	return strings.ToLower(code)
+}
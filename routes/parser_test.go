@@ -0,0 +1,88 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*routesCollector)
+	golden.TestParsers(t, c.parsers, "routes")
+}
+
+func TestParseMalformedRoutes(t *testing.T) {
+	c := &routesCollector{}
+	cxHeader := "VRF: default\n\nPrefix              Nexthop                                  Interface     VRF(egress)       Origin/   Distance/    Age\n"
+	switchHeader := "  Destination        Gateway         VLAN Type      Sub-Type   Metric     Dist.\n"
+	instantHeader := "Destination     Gateway         Genmask         Flags   MSS Window  irtt Iface\n"
+
+	tests := []struct {
+		name   string
+		parse  func(string) (map[string]*VRFRoutes, error)
+		output string
+		want   map[string]map[string]float64
+	}{
+		{name: "empty output", parse: c.ParseArubaCXRoutes, want: map[string]map[string]float64{}},
+		{name: "empty table", parse: c.ParseArubaCXRoutes, output: cxHeader, want: map[string]map[string]float64{"default": {}}},
+		{
+			name:   "missing distance column",
+			parse:  c.ParseArubaCXRoutes,
+			output: cxHeader + "10.0.10.0/24        -                                        vlan10        -                 C         -\n",
+			want:   map[string]map[string]float64{"default": {}},
+		},
+		{
+			name:   "non-numeric distance",
+			parse:  c.ParseArubaCXRoutes,
+			output: cxHeader + "10.0.10.0/24        -                                        vlan10        -                 C         [x/0]        -\n",
+			want:   map[string]map[string]float64{"default": {}},
+		},
+		{name: "AOS-S empty output", parse: c.ParseArubaSwitchRoutes, want: map[string]map[string]float64{}},
+		{name: "AOS-S empty table", parse: c.ParseArubaSwitchRoutes, output: switchHeader, want: map[string]map[string]float64{}},
+		{
+			name:   "AOS-S missing type column",
+			parse:  c.ParseArubaSwitchRoutes,
+			output: switchHeader + "  0.0.0.0/0          10.0.0.1        1\n",
+			want:   map[string]map[string]float64{},
+		},
+		{name: "controller empty output", parse: c.ParseArubaControllerRoutes, want: map[string]map[string]float64{}},
+		{
+			name:   "controller next hop without a route",
+			parse:  c.ParseArubaControllerRoutes,
+			output: "                      [110/2] via 10.10.10.3*\n",
+			want:   map[string]map[string]float64{},
+		},
+		{name: "Instant empty output", parse: c.ParseArubaInstantRoutes, want: map[string]map[string]float64{}},
+		{name: "Instant empty table", parse: c.ParseArubaInstantRoutes, output: "Kernel IP routing table\n" + instantHeader, want: map[string]map[string]float64{}},
+		{
+			name:   "Instant missing genmask column",
+			parse:  c.ParseArubaInstantRoutes,
+			output: instantHeader + "default         192.168.100.1   UG        0 0          0 br0\n",
+			want:   map[string]map[string]float64{},
+		},
+		{
+			name:   "Instant non-contiguous genmask",
+			parse:  c.ParseArubaInstantRoutes,
+			output: instantHeader + "172.31.98.0     0.0.0.0         255.0.255.0     U         0 0          0 br0:1\n",
+			want:   map[string]map[string]float64{},
+		},
+	}
+
+	for _, test := range tests {
+		vrfs, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, vrfs)
+
+		got := make(map[string]map[string]float64)
+		for vrf, r := range vrfs {
+			got[vrf] = r.Routes
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got routes %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package routes
+
+// VRFRoutes is the number of routes per protocol in the routing table of a VRF
+type VRFRoutes struct {
+	VRF          string
+	Routes       map[string]float64
+	DefaultRoute bool
+
+	prefixes map[string]bool
+}
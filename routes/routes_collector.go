@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_routes_"
+
+var (
+	RoutesDesc       *prometheus.Desc
+	DefaultRouteDesc *prometheus.Desc
+)
+
+func init() {
+	collector.Register("routes", false, NewCollector)
+
+	RoutesDesc = collector.NewDesc(prefix+"count", "Number of IPv4 routes in the routing table", []string{"target", "vrf", "protocol"})
+	DefaultRouteDesc = collector.NewDesc(prefix+"default_route", "Routing table has a default route (0.0.0.0/0)", []string{"target", "vrf"})
+}
+
+type routesCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &routesCollector{
+		parsers: collector.NewParsers("routes"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *routesCollector) registerParsers() {
+	c.parsers.Register("routes", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show ip route all-vrfs"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXRoutes(out) },
+	})
+	c.parsers.Register("routes", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show ip route"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchRoutes(out) },
+	})
+	c.parsers.Register("routes", &collector.Parser{
+		OSType:   rpc.ArubaController,
+		Commands: []string{"show ip route"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaControllerRoutes(out) },
+	})
+	c.parsers.Register("routes", &collector.Parser{
+		OSType:   rpc.ArubaInstant,
+		Commands: []string{"show ip route"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaInstantRoutes(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *routesCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*routesCollector) Name() string {
+	return "Routes"
+}
+
+func (c *routesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- RoutesDesc
+	ch <- DefaultRouteDesc
+}
+
+func (c *routesCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	parsed, err := collector.Run(c.parsers, "routes", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+
+	for vrf, r := range parsed.(map[string]*VRFRoutes) {
+		l := append(labelValues, vrf)
+
+		for protocol, count := range r.Routes {
+			ch <- prometheus.MustNewConstMetric(RoutesDesc, prometheus.GaugeValue, count, append(l, protocol)...)
+		}
+
+		defaultRoute := 0
+		if r.DefaultRoute {
+			defaultRoute = 1
+		}
+		ch <- prometheus.MustNewConstMetric(DefaultRouteDesc, prometheus.GaugeValue, float64(defaultRoute), l...)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 
 	"github.com/slashdoom/aruba_exporter/connector"
@@ -16,20 +17,53 @@ const (
 	ArubaCXSwitch string = "ArubaCXSwitch"
 )
 
+// OSTypes are all supported OS types
+var OSTypes = []string{ArubaInstant, ArubaController, ArubaSwitch, ArubaCXSwitch}
+
+// IsSupportedOSType checks if the OS type is one of the supported Aruba OS types
+func IsSupportedOSType(osType string) bool {
+	switch osType {
+	case ArubaInstant, ArubaController, ArubaSwitch, ArubaCXSwitch:
+		return true
+	default:
+		return false
+	}
+}
+
+var versionRegexp = map[string]*regexp.Regexp{
+	ArubaInstant:    regexp.MustCompile(`(?m), Version (\d+(?:\.\d+)+)`),
+	ArubaController: regexp.MustCompile(`(?m), Version (\d+(?:\.\d+)+)`),
+	ArubaSwitch:     regexp.MustCompile(`(?m)^\s*[A-Z]{2}\.(\d+\.\d+\.\d+)\s*$`),
+	ArubaCXSwitch:   regexp.MustCompile(`(?m)^Version\s*:\s*[A-Z]{2}\.(\d+\.\d+\.\d+)`),
+}
+
+// Connection runs commands on a device
+type Connection interface {
+	RunCommand(cmds []string) (string, error)
+}
+
 // Client sends commands to a Aruba device
 type Client struct {
-	conn   *connector.SSHConnection
-	Level  string
-	OSType string
+	conn    Connection
+	host    string
+	Level   string
+	OSType  string
+	Version string
 }
 
 // NewClient creates a new client connection
 func NewClient(ssh *connector.SSHConnection, level string) *Client {
-	rpc := &Client{conn: ssh, Level: level}
+	rpc := &Client{conn: ssh, host: ssh.Host, Level: level}
 
 	return rpc
 }
 
+// NewClientForConnection creates a client for any connection (e.g. saved command outputs).
+// The OS type and version are not identified, they have to be set by the caller.
+func NewClientForConnection(conn Connection, host string, level string) *Client {
+	return &Client{conn: conn, host: host, Level: level}
+}
+
 // Identify tries to identify the OS running on a Aruba device
 func (c *Client) Identify() error {
 	output, err := c.RunCommand([]string{"show version"})
@@ -52,11 +86,28 @@ func (c *Client) Identify() error {
 		return errors.New("Unknown OS")
 	}
 
-	log.Infof("Host %s identified as: %s\n", c.conn.Host, c.OSType)
+	c.Version = FirmwareVersion(c.OSType, output)
+
+	log.Infof("Host %s identified as: %s %s\n", c.host, c.OSType, c.Version)
 
 	return nil
 }
 
+// FirmwareVersion finds the firmware version (e.g. 10.10.0002) in the output of show version
+func FirmwareVersion(osType, output string) string {
+	re, found := versionRegexp[osType]
+	if !found {
+		return ""
+	}
+
+	matches := re.FindStringSubmatch(strings.ReplaceAll(output, "\r", ""))
+	if matches == nil {
+		return ""
+	}
+
+	return matches[1]
+}
+
 // RunCommand runs a command or commands on Aruba devices
 func (c *Client) RunCommand(cmds []string) (string, error) {
 
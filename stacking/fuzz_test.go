@@ -0,0 +1,19 @@
+package stacking
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseStack(f *testing.F) {
+	golden.Seeds(f, "stacking")
+	c := NewCollector().(*stackingCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("stack") {
+			stack, _ := p.Parse(out)
+			golden.CheckValues(t, stack)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package stacking
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/util"
+)
+
+var (
+	separatorRegexp = regexp.MustCompile(`^[\s-]*-{3,}[\s-]*$`)
+	dashesRegexp    = regexp.MustCompile(`-+`)
+	fieldRegexp     = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9 ]*?)\s*:\s*(\S.*?)\s*$`)
+	vsfMemberRegexp = regexp.MustCompile(`^VSF Member (\d+)\s*$`)
+	vsfLinkRegexp   = regexp.MustCompile(`^(?i)vsf link (\d+)$`)
+	vsfPeerRegexp   = regexp.MustCompile(`(?i)peer member (\d+), link (\d+)`)
+)
+
+// ParseArubaSwitchStack parses "show stacking" and "show stacking stack-ports". The member table of the first has
+// the ID, MAC address, model, priority and status of the members, the second has the state and peer of each stacking port.
+func (c *stackingCollector) ParseArubaSwitchStack(output string) (Stack, error) {
+	stack := Stack{}
+
+	var spans [][]int
+	var header string
+	var previous string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if separatorRegexp.MatchString(line) {
+			spans = dashesRegexp.FindAllStringIndex(line, -1)
+			header = strings.ToLower(strings.Join(strings.Fields(previous), " "))
+			continue
+		}
+		previous = line
+
+		if spans == nil {
+			continue
+		}
+		if len(strings.TrimSpace(line)) == 0 {
+			spans = nil
+			continue
+		}
+
+		// rows without an ID continue the row above (e.g. a wrapped model)
+		columns := splitColumns(line, spans)
+		if len(columns) == 0 || len(columns[0]) == 0 {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(header, "pri status") && len(columns) == 5:
+			stack.Members = append(stack.Members, &Member{
+				ID:         columns[0],
+				MACAddress: columns[1],
+				Model:      columns[2],
+				Priority:   util.Str2float64(columns[3]),
+				Status:     columns[4],
+				Role:       role(columns[4]),
+			})
+		case strings.HasPrefix(header, "member stacking port") && len(columns) == 5:
+			link := &Link{
+				Member: columns[0],
+				Link:   columns[1],
+				State:  columns[2],
+			}
+			if columns[3] != "0" {
+				link.PeerMember = columns[3]
+				link.PeerLink = columns[4]
+			}
+			stack.Links = append(stack.Links, link)
+		}
+	}
+
+	return stack, nil
+}
+
+// ParseArubaCXStack parses "show vsf" and "show vsf detail". The member table of the first has the ID, MAC address,
+// type and status of the members, the per member sections of the second add the description and the VSF links.
+func (c *stackingCollector) ParseArubaCXStack(output string) (Stack, error) {
+	stack := Stack{}
+	members := make(map[string]*Member)
+
+	member := func(id string) *Member {
+		m, found := members[id]
+		if !found {
+			m = &Member{ID: id, Priority: -1}
+			members[id] = m
+			stack.Members = append(stack.Members, m)
+		}
+		return m
+	}
+
+	var spans [][]int
+	var current *Member
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if separatorRegexp.MatchString(line) {
+			spans = dashesRegexp.FindAllStringIndex(line, -1)
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			spans = nil
+			continue
+		}
+
+		if spans != nil {
+			columns := splitColumns(line, spans)
+			if len(columns) != 4 || len(columns[0]) == 0 {
+				continue
+			}
+			m := member(columns[0])
+			m.MACAddress = columns[1]
+			m.Model = columns[2]
+			m.Status = columns[3]
+			continue
+		}
+
+		if match := vsfMemberRegexp.FindStringSubmatch(line); match != nil {
+			current = member(match[1])
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			current = nil
+			continue
+		}
+
+		match := fieldRegexp.FindStringSubmatch(line)
+		if current == nil || match == nil {
+			continue
+		}
+
+		switch strings.ToLower(match[1]) {
+		case "status":
+			current.Status = match[2]
+		case "type":
+			current.Model = match[2]
+		case "description":
+			current.Description = match[2]
+		case "mac address":
+			current.MACAddress = match[2]
+		default:
+			l := vsfLinkRegexp.FindStringSubmatch(match[1])
+			if l == nil {
+				continue
+			}
+			link := &Link{
+				Member: current.ID,
+				Link:   l[1],
+				State:  strings.TrimSpace(strings.SplitN(match[2], ",", 2)[0]),
+			}
+			if p := vsfPeerRegexp.FindStringSubmatch(match[2]); p != nil {
+				link.PeerMember = p[1]
+				link.PeerLink = p[2]
+			}
+			stack.Links = append(stack.Links, link)
+		}
+	}
+
+	for _, m := range stack.Members {
+		m.Role = role(m.Status)
+	}
+
+	return stack, nil
+}
+
+// splitColumns cuts a table row at the start of the columns of the dashed separator line below the header
+func splitColumns(line string, spans [][]int) []string {
+	columns := make([]string, 0, len(spans))
+	for i, span := range spans {
+		start := span[0]
+		if i == 0 {
+			start = 0
+		}
+		if start >= len(line) {
+			columns = append(columns, "")
+			continue
+		}
+
+		end := len(line)
+		if i+1 < len(spans) && spans[i+1][0] < end {
+			end = spans[i+1][0]
+		}
+		columns = append(columns, strings.TrimSpace(line[start:end]))
+	}
+
+	return columns
+}
+
+// role gets the role of a member from its status. AOS-CX calls the commander conductor (master on older releases).
+func role(status string) string {
+	switch strings.ToLower(status) {
+	case "commander", "conductor", "master":
+		return "commander"
+	case "standby":
+		return "standby"
+	case "member":
+		return "member"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,91 @@
+package stacking
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*stackingCollector)
+	golden.TestParsers(t, c.parsers, "stacking")
+}
+
+func TestParseMalformedOutput(t *testing.T) {
+	c := &stackingCollector{}
+	switchMembers := " Mbr\n ID  Mac Address       Model                                 Pri Status\n --- ----------------- ------------------------------------- --- ---------------\n"
+	switchPorts := " Member Stacking Port State   Peer Member Peer Port\n ------ ------------- ------- ----------- ---------\n"
+	cxMembers := "Mbr  MAC Address         type           Status\nID\n---  -------------------  -------------  ---------------\n"
+
+	tests := []struct {
+		name   string
+		parse  func(string) (Stack, error)
+		output string
+		want   Stack
+	}{
+		{name: "empty output", parse: c.ParseArubaSwitchStack},
+		{name: "empty member table", parse: c.ParseArubaSwitchStack, output: switchMembers + "\n"},
+		{
+			name:   "non-numeric priority",
+			parse:  c.ParseArubaSwitchStack,
+			output: switchMembers + " 1   a0b3c4-d5e6f7     Aruba JL322A 2930M-48G-PoE+ Switch    n/a Commander\n",
+			want:   Stack{Members: []*Member{{ID: "1", MACAddress: "a0b3c4-d5e6f7", Model: "Aruba JL322A 2930M-48G-PoE+ Switch", Priority: -1, Status: "Commander", Role: "commander"}}},
+		},
+		{
+			name:   "missing priority and status columns",
+			parse:  c.ParseArubaSwitchStack,
+			output: switchMembers + " 1   a0b3c4-d5e6f7     Aruba JL322A 2930M-48G-PoE+ Switch\n",
+			want:   Stack{Members: []*Member{{ID: "1", MACAddress: "a0b3c4-d5e6f7", Model: "Aruba JL322A 2930M-48G-PoE+ Switch", Priority: -1}}},
+		},
+		{
+			name:   "wrapped rows without ID",
+			parse:  c.ParseArubaSwitchStack,
+			output: switchMembers + "                       Switch\n                       Switch\n",
+		},
+		{
+			name:   "stacking port without peer columns",
+			parse:  c.ParseArubaSwitchStack,
+			output: switchPorts + " 1      1             Up\n",
+			want:   Stack{Links: []*Link{{Member: "1", Link: "1", State: "Up"}}},
+		},
+		{name: "VSF empty output", parse: c.ParseArubaCXStack},
+		{name: "VSF empty member table", parse: c.ParseArubaCXStack, output: cxMembers + "\n"},
+		{
+			name:   "VSF member without status column",
+			parse:  c.ParseArubaCXStack,
+			output: cxMembers + " 1   38:21:c7:5f:a1:c0    JL668A\n",
+			want:   Stack{Members: []*Member{{ID: "1", MACAddress: "38:21:c7:5f:a1:c0", Model: "JL668A", Priority: -1}}},
+		},
+		{
+			name:   "VSF details without member",
+			parse:  c.ParseArubaCXStack,
+			output: "  Status                   : Conductor\n  VSF Link 1               : Up, connected to peer member 2, link 1\n",
+		},
+		{
+			name:   "VSF link without state",
+			parse:  c.ParseArubaCXStack,
+			output: "VSF Member 1\n  Status                   : Conductor\n  VSF Link 1               :\n",
+			want:   Stack{Members: []*Member{{ID: "1", Priority: -1, Status: "Conductor", Role: "commander"}}},
+		},
+	}
+
+	for _, test := range tests {
+		stack, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, stack)
+
+		if !reflect.DeepEqual(stack, test.want) {
+			t.Errorf("%s: got %s, want %s", test.name, format(stack), format(test.want))
+		}
+	}
+}
+
+func format(s Stack) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
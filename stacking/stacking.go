@@ -0,0 +1,27 @@
+package stacking
+
+// Stack is the membership of a stack (AOS-S) or a VSF stack (AOS-CX) and the links between its members
+type Stack struct {
+	Members []*Member
+	Links   []*Link
+}
+
+// Member is a switch in the stack. Role is empty if the member is not active in the stack, Priority is -1 if unknown.
+type Member struct {
+	ID          string
+	MACAddress  string
+	Model       string
+	Description string
+	Priority    float64
+	Status      string
+	Role        string
+}
+
+// Link is a stacking port (AOS-S) or VSF link (AOS-CX) of a member
+type Link struct {
+	Member     string
+	Link       string
+	State      string
+	PeerMember string
+	PeerLink   string
+}
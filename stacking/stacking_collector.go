@@ -0,0 +1,107 @@
+package stacking
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_stacking_"
+
+var (
+	MemberUpDesc       *prometheus.Desc
+	MemberInfoDesc     *prometheus.Desc
+	MemberPriorityDesc *prometheus.Desc
+	LinkUpDesc         *prometheus.Desc
+)
+
+func init() {
+	collector.Register("stacking", false, NewCollector)
+
+	l := []string{"target", "member"}
+
+	MemberUpDesc = collector.NewDesc(prefix+"member_up", "Member is active in the stack (commander, standby or member)", l)
+	MemberInfoDesc = collector.NewDesc(prefix+"member_info", "Model, role and status of the stack member", append(l, "mac_address", "model", "role", "status"))
+	MemberPriorityDesc = collector.NewDesc(prefix+"member_priority", "Commander election priority of the stack member", l)
+	LinkUpDesc = collector.NewDesc(prefix+"link_up", "Stacking port or VSF link of the member is up", append(l, "link"))
+}
+
+type stackingCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &stackingCollector{
+		parsers: collector.NewParsers("stacking"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *stackingCollector) registerParsers() {
+	c.parsers.Register("stack", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show stacking", "show stacking stack-ports"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaSwitchStack(out) },
+	})
+	c.parsers.Register("stack", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show vsf", "show vsf detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXStack(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *stackingCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*stackingCollector) Name() string {
+	return "Stacking"
+}
+
+func (c *stackingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- MemberUpDesc
+	ch <- MemberInfoDesc
+	ch <- MemberPriorityDesc
+	ch <- LinkUpDesc
+}
+
+func (c *stackingCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("stack", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "stack", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	stack := parsed.(Stack)
+
+	for _, m := range stack.Members {
+		l := append(labelValues, m.ID)
+
+		ch <- prometheus.MustNewConstMetric(MemberUpDesc, prometheus.GaugeValue, bool2float64(m.Role != ""), l...)
+		ch <- prometheus.MustNewConstMetric(MemberInfoDesc, prometheus.GaugeValue, 1, append(l, m.MACAddress, m.Model, m.Role, m.Status)...)
+		if m.Priority >= 0 {
+			ch <- prometheus.MustNewConstMetric(MemberPriorityDesc, prometheus.GaugeValue, m.Priority, l...)
+		}
+	}
+
+	for _, link := range stack.Links {
+		ch <- prometheus.MustNewConstMetric(LinkUpDesc, prometheus.GaugeValue, bool2float64(link.State == "Up"), append(labelValues, link.Member, link.Link)...)
+	}
+
+	return nil
+}
+
+func bool2float64(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
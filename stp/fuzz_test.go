@@ -0,0 +1,19 @@
+package stp
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseInstances(f *testing.F) {
+	golden.Seeds(f, "stp")
+	c := NewCollector().(*stpCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("instances") {
+			instances, _ := p.Parse(out)
+			golden.CheckValues(t, instances)
+		}
+	})
+}
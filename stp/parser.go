@@ -0,0 +1,260 @@
+package stp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/util"
+)
+
+var (
+	fieldRegexp        = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z -]*?)\s*:\s*(\S.*?)\s*$`)
+	instanceRegexp     = regexp.MustCompile(`^(?:#+\s*)?MST(\d+)\s*$`)
+	rootAddressRegexp  = regexp.MustCompile(`^Root\s+Address:\s*(\S+)\s+priority:\s*(\d+)`)
+	rootPortRegexp     = regexp.MustCompile(`^\s+Port:\s*([^,\s]+),`)
+	separatorRegexp    = regexp.MustCompile(`^[\s+-]*-{3,}[\s+-]*$`)
+	dashesRegexp       = regexp.MustCompile(`-+`)
+	durationPartRegexp = regexp.MustCompile(`(\d+)\s*([A-Za-z]+)`)
+	countRegexp        = regexp.MustCompile(`^\d+$`)
+)
+
+// ParseInstances parses "show spanning-tree detail" and "show spanning-tree mst detail" of AOS-CX and
+// "show spanning-tree detail" of AOS-S. AOS-CX starts a section per MST instance with "MST<n>", AOS-S only shows the
+// CST/IST (instance 0). Sections of the same instance are merged. The root port is the port with the role root if
+// the output does not name it.
+func (c *stpCollector) ParseInstances(output string) ([]*Instance, error) {
+	instances := []*Instance{}
+	byName := make(map[string]*Instance)
+	instance := func(name string) *Instance {
+		i, found := byName[name]
+		if !found {
+			i = &Instance{Instance: name, TopologyChanges: -1, SinceTopologyChange: -1}
+			byName[name] = i
+			instances = append(instances, i)
+		}
+		return i
+	}
+
+	var current *Instance
+	var section, rootMAC, rootPriority string
+	var spans [][]int
+	var names []string
+	header := []string{}
+
+	setRoot := func() {
+		if current != nil && rootMAC != "" && rootPriority != "" {
+			current.RootBridgeID = bridgeID(rootPriority, rootMAC)
+		}
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := instanceRegexp.FindStringSubmatch(line); m != nil {
+			current = instance(m[1])
+			section, rootMAC, rootPriority = "", "", ""
+			continue
+		}
+		if current == nil && len(strings.TrimSpace(line)) > 0 {
+			current = instance("0")
+		}
+
+		if separatorRegexp.MatchString(line) {
+			spans = dashesRegexp.FindAllStringIndex(line, -1)
+			names = make([]string, len(spans))
+			for _, h := range header {
+				for i, name := range splitColumns(h, spans) {
+					names[i] = strings.TrimSpace(names[i] + " " + name)
+				}
+			}
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			spans = nil
+			header = header[:0]
+			continue
+		}
+
+		if spans != nil {
+			addPort(current, names, splitColumns(line, spans))
+			continue
+		}
+		header = append(header, line)
+
+		if m := rootAddressRegexp.FindStringSubmatch(line); m != nil {
+			rootMAC, rootPriority = m[1], m[2]
+			section = "root"
+			setRoot()
+			continue
+		}
+		if m := rootPortRegexp.FindStringSubmatch(line); m != nil && section == "root" {
+			current.RootPort = rootPort(m[1])
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			section = ""
+		}
+		if strings.HasPrefix(line, "  Root ID") {
+			section = "root"
+		} else if strings.HasPrefix(line, "  Bridge ID") {
+			section = "bridge"
+		}
+
+		m := fieldRegexp.FindStringSubmatch(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(line), "Root ID"), "Bridge ID"))
+		if m == nil {
+			continue
+		}
+
+		switch strings.ToLower(m[1]) {
+		case "priority":
+			if section == "root" {
+				rootPriority = m[2]
+				setRoot()
+			}
+		case "mac-address":
+			if section == "root" {
+				rootMAC = m[2]
+				setRoot()
+			}
+		case "cst root mac address":
+			rootMAC = m[2]
+			setRoot()
+		case "cst root priority":
+			rootPriority = m[2]
+			setRoot()
+		case "cst root port":
+			current.RootPort = rootPort(m[2])
+		case "number of topology changes", "topology change count":
+			if countRegexp.MatchString(m[2]) {
+				current.TopologyChanges = util.Str2float64(m[2])
+			}
+		case "last topology change occurred", "time since last change":
+			current.SinceTopologyChange = durationSeconds(m[2])
+		}
+	}
+
+	for _, i := range instances {
+		if i.RootPort != "" {
+			continue
+		}
+		for _, p := range i.Ports {
+			if p.Role == "root" {
+				i.RootPort = p.Port
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+// addPort adds or updates the port of a row of a port table, the names are the headers of the columns
+func addPort(instance *Instance, names []string, columns []string) {
+	port := &Port{}
+	for i, value := range columns {
+		value = strings.TrimSpace(strings.Trim(value, "|"))
+		switch strings.ToLower(strings.TrimSpace(strings.Trim(names[i], "|"))) {
+		case "port":
+			port.Port = value
+		case "role":
+			port.Role = strings.ToLower(value)
+		case "state":
+			port.State = strings.ToLower(value)
+		}
+	}
+	if port.Port == "" || port.State == "" {
+		return
+	}
+
+	for _, p := range instance.Ports {
+		if p.Port == port.Port {
+			if port.Role != "" {
+				p.Role = port.Role
+			}
+			p.State = port.State
+			return
+		}
+	}
+	instance.Ports = append(instance.Ports, port)
+}
+
+// rootPort gets the root port, which is "0" if the switch is the root
+func rootPort(s string) string {
+	if s == "0" {
+		return ""
+	}
+
+	return s
+}
+
+// bridgeID formats a bridge ID as the hexadecimal priority and MAC address, e.g. "1000.3821c7112233"
+func bridgeID(priority string, mac string) string {
+	p, err := strconv.ParseUint(priority, 10, 16)
+	mac = strings.ToLower(strings.ReplaceAll(util.StandardizeMacAddr(mac), "-", ""))
+	if err != nil || mac == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%04x.%s", p, mac)
+}
+
+// durationSeconds converts durations like "3600 seconds ago", "2 hours" or "1 day 3 hours" to seconds, it is -1 if there are no parts
+// or a part is out of range
+func durationSeconds(s string) float64 {
+	parts := durationPartRegexp.FindAllStringSubmatch(s, -1)
+	if len(parts) == 0 {
+		return -1
+	}
+
+	seconds := float64(0)
+	for _, p := range parts {
+		v := util.Str2float64(p[1])
+		if v == -1 {
+			return -1
+		}
+		switch unit := strings.ToLower(p[2]); {
+		case strings.HasPrefix(unit, "w"):
+			seconds += v * 604800
+		case strings.HasPrefix(unit, "d"):
+			seconds += v * 86400
+		case strings.HasPrefix(unit, "h"):
+			seconds += v * 3600
+		case strings.HasPrefix(unit, "m"):
+			seconds += v * 60
+		case strings.HasPrefix(unit, "s"):
+			seconds += v
+		}
+	}
+
+	if math.IsInf(seconds, 0) {
+		return -1
+	}
+
+	return seconds
+}
+
+// splitColumns cuts a table row at the start of the columns of the dashed separator line below the header
+func splitColumns(line string, spans [][]int) []string {
+	columns := make([]string, 0, len(spans))
+	for i, span := range spans {
+		start := span[0]
+		if i == 0 {
+			start = 0
+		}
+		if start >= len(line) {
+			columns = append(columns, "")
+			continue
+		}
+
+		end := len(line)
+		if i+1 < len(spans) && spans[i+1][0] < end {
+			end = spans[i+1][0]
+		}
+		columns = append(columns, strings.TrimSpace(line[start:end]))
+	}
+
+	return columns
+}
@@ -0,0 +1,92 @@
+package stp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*stpCollector)
+	golden.TestParsers(t, c.parsers, "stp")
+}
+
+func TestParseInstancesInvalidValues(t *testing.T) {
+	c := &stpCollector{}
+
+	for _, out := range []string{
+		"Topology change count : -3\n",
+		"Topology change count : NaN\n",
+		"Time since last change : 1" + strings.Repeat("0", 400) + " weeks\n",
+		"Time since last change : 1" + strings.Repeat("0", 305) + " weeks\n",
+	} {
+		instances, err := c.ParseInstances(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(instances) != 1 || instances[0].TopologyChanges != -1 || instances[0].SinceTopologyChange != -1 {
+			t.Errorf("%q: got %+v, want -1 for invalid values", out, instances[0])
+		}
+	}
+}
+
+func TestParseMalformedInstances(t *testing.T) {
+	c := &stpCollector{}
+	table := "Port         Role           State      Cost    Priority   Type\n------------ -------------- ---------- ------- ---------- ----------\n"
+
+	tests := []struct {
+		name   string
+		output string
+		want   []*Instance
+	}{
+		{name: "empty output", output: "\n\n", want: []*Instance{}},
+		{
+			name:   "empty port table",
+			output: table + "\n",
+			want:   []*Instance{{Instance: "0", TopologyChanges: -1, SinceTopologyChange: -1}},
+		},
+		{
+			name:   "rows without a header",
+			output: "MST1\n1/1/1        Designated     Forwarding 20000   128        P2P\n",
+			want:   []*Instance{{Instance: "1", TopologyChanges: -1, SinceTopologyChange: -1}},
+		},
+		{
+			name:   "rows without state column",
+			output: "MST1\n" + table + "1/1/1        Designated\n1/1/2\n",
+			want:   []*Instance{{Instance: "1", TopologyChanges: -1, SinceTopologyChange: -1}},
+		},
+		{
+			name:   "root without MAC address",
+			output: "MST0\n  Root ID    Priority   : 4096\n",
+			want:   []*Instance{{Instance: "0", TopologyChanges: -1, SinceTopologyChange: -1}},
+		},
+		{
+			name:   "non-numeric root priority",
+			output: "MST0\n  Root ID    Priority   : n/a\n             MAC-Address: 38:21:c7:11:22:33\n",
+			want:   []*Instance{{Instance: "0", TopologyChanges: -1, SinceTopologyChange: -1}},
+		},
+		{
+			name:   "duration without number",
+			output: "MST0\nLast topology change occurred   : seconds ago\n",
+			want:   []*Instance{{Instance: "0", TopologyChanges: -1, SinceTopologyChange: -1}},
+		},
+	}
+
+	for _, test := range tests {
+		instances, err := c.ParseInstances(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, instances)
+
+		if !reflect.DeepEqual(instances, test.want) {
+			got, _ := json.Marshal(instances)
+			want, _ := json.Marshal(test.want)
+			t.Errorf("%s: got %s, want %s", test.name, got, want)
+		}
+	}
+}
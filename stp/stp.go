@@ -0,0 +1,20 @@
+package stp
+
+// Instance is a spanning tree instance (MST instance, 0 is the CIST). RootBridgeID is the priority and MAC address
+// of the root bridge as hexadecimal "pppp.mmmmmmmmmmmm", RootPort is empty if the switch is the root.
+// TopologyChanges and SinceTopologyChange (seconds) are -1 if unknown.
+type Instance struct {
+	Instance            string
+	RootBridgeID        string
+	RootPort            string
+	TopologyChanges     float64
+	SinceTopologyChange float64
+	Ports               []*Port
+}
+
+// Port is the role (root, designated, alternate, ...) and state (forwarding, blocking, ...) of a port in an instance
+type Port struct {
+	Port  string
+	Role  string
+	State string
+}
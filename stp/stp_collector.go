@@ -0,0 +1,114 @@
+package stp
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_stp_"
+
+var (
+	RootInfoDesc            *prometheus.Desc
+	TopologyChangesDesc     *prometheus.Desc
+	SinceTopologyChangeDesc *prometheus.Desc
+	PortStateDesc           *prometheus.Desc
+	PortForwardingDesc      *prometheus.Desc
+)
+
+func init() {
+	collector.Register("stp", false, NewCollector)
+
+	l := []string{"target", "instance"}
+
+	RootInfoDesc = collector.NewDesc(prefix+"root_info", "Root bridge ID and root port of the spanning tree instance, the root port is empty if the switch is the root", append(l, "root_bridge_id", "root_port"))
+	TopologyChangesDesc = collector.NewDesc(prefix+"topology_changes_total", "Number of topology changes of the spanning tree instance", l)
+	SinceTopologyChangeDesc = collector.NewDesc(prefix+"seconds_since_topology_change", "Time since the last topology change of the spanning tree instance", l)
+	PortStateDesc = collector.NewDesc(prefix+"port_state", "Role and state of the port in the spanning tree instance", append(l, "port", "role", "state"))
+	PortForwardingDesc = collector.NewDesc(prefix+"port_forwarding", "Port is forwarding in the spanning tree instance", append(l, "port"))
+}
+
+type stpCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &stpCollector{
+		parsers: collector.NewParsers("stp"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *stpCollector) registerParsers() {
+	c.parsers.Register("instances", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show spanning-tree detail", "show spanning-tree mst detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseInstances(out) },
+	})
+	c.parsers.Register("instances", &collector.Parser{
+		OSType:   rpc.ArubaSwitch,
+		Commands: []string{"show spanning-tree detail"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseInstances(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *stpCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*stpCollector) Name() string {
+	return "STP"
+}
+
+func (c *stpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- RootInfoDesc
+	ch <- TopologyChangesDesc
+	ch <- SinceTopologyChangeDesc
+	ch <- PortStateDesc
+	ch <- PortForwardingDesc
+}
+
+func (c *stpCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("instances", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "instances", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range parsed.([]*Instance) {
+		l := append(labelValues, instance.Instance)
+
+		if instance.RootBridgeID != "" {
+			ch <- prometheus.MustNewConstMetric(RootInfoDesc, prometheus.GaugeValue, 1, append(l, instance.RootBridgeID, instance.RootPort)...)
+		}
+		if instance.TopologyChanges >= 0 {
+			ch <- prometheus.MustNewConstMetric(TopologyChangesDesc, prometheus.CounterValue, instance.TopologyChanges, l...)
+		}
+		if instance.SinceTopologyChange >= 0 {
+			ch <- prometheus.MustNewConstMetric(SinceTopologyChangeDesc, prometheus.GaugeValue, instance.SinceTopologyChange, l...)
+		}
+
+		for _, port := range instance.Ports {
+			ch <- prometheus.MustNewConstMetric(PortStateDesc, prometheus.GaugeValue, 1, append(l, port.Port, port.Role, port.State)...)
+			ch <- prometheus.MustNewConstMetric(PortForwardingDesc, prometheus.GaugeValue, bool2float64(port.State == "forwarding"), append(l, port.Port)...)
+		}
+	}
+
+	return nil
+}
+
+func bool2float64(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
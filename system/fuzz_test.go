@@ -0,0 +1,55 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+func FuzzParseVersion(f *testing.F) {
+	golden.Seeds(f, "system")
+	c := &systemCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, osType := range rpc.OSTypes {
+			c.ParseVersion(osType, out)
+		}
+	})
+}
+
+func FuzzParseUptime(f *testing.F) {
+	golden.Seeds(f, "system")
+	c := &systemCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, osType := range rpc.OSTypes {
+			item, _ := c.ParseUptime(osType, out)
+			golden.CheckValues(t, item)
+		}
+	})
+}
+
+func FuzzParseMemory(f *testing.F) {
+	golden.Seeds(f, "system")
+	c := &systemCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, osType := range rpc.OSTypes {
+			items, _ := c.ParseMemory(osType, out)
+			golden.CheckValues(t, items)
+		}
+	})
+}
+
+func FuzzParseCPU(f *testing.F) {
+	golden.Seeds(f, "system")
+	c := &systemCollector{}
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, osType := range rpc.OSTypes {
+			items, _ := c.ParseCPU(osType, out)
+			golden.CheckValues(t, items)
+		}
+	})
+}
@@ -255,7 +255,7 @@ func (c *systemCollector) ParseMemory(ostype string, output string) ([]SystemMem
 				Type: fmt.Sprintf("system"),
 				Total: totalMem.Value,
 				Used: usedMem.Value,
-				Free: (totalMem.Value - usedMem.Value),
+				Free: util.Remaining(totalMem.Value, usedMem.Value),
 			}
 			log.Debugf("item: %+v\n", item)
 			items = append(items, item)
@@ -293,7 +293,7 @@ func (c *systemCollector) ParseMemory(ostype string, output string) ([]SystemMem
 				Type: fmt.Sprintf("system"),
 				Total: math.RoundToEven(totalMem.Value/1000),
 				Used: math.RoundToEven(usedMem.Value/1000),
-				Free: math.RoundToEven((totalMem.Value - usedMem.Value)/1000),
+				Free: math.RoundToEven(util.Remaining(totalMem.Value, usedMem.Value)/1000),
 			}
 			log.Debugf("item: %+v\n", item)
 			items = append(items, item)
@@ -410,7 +410,7 @@ func (c *systemCollector) ParseCPU(ostype string, output string) ([]SystemCPU, e
 			item := SystemCPU{
 				Type: "total",
 				Used: util.Str2float64(matches[1]),
-				Idle: 100-util.Str2float64(matches[1]),
+				Idle: util.Remaining(100, util.Str2float64(matches[1])),
 			}
 			log.Debugf("item: %+v\n", item)
 			items = append(items, item)
@@ -429,7 +429,7 @@ func (c *systemCollector) ParseCPU(ostype string, output string) ([]SystemCPU, e
 			item := SystemCPU{
 				Type: "total",
 				Used: util.Str2float64(matches[1]),
-				Idle: 100-util.Str2float64(matches[1]),
+				Idle: util.Remaining(100, util.Str2float64(matches[1])),
 			}
 			log.Debugf("item: %+v\n", item)
 			items = append(items, item)
@@ -0,0 +1,12 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*systemCollector)
+	golden.TestParsers(t, c.parsers, "system")
+}
@@ -21,24 +21,89 @@ var (
 )
 
 func init() {
+	collector.Register("system", true, NewCollector)
+
 	l := []string{"target"}
-	versionDesc = prometheus.NewDesc(prefix+"version", "Running OS version", append(l, "version"), nil)
-	uptimeDesc = prometheus.NewDesc(prefix+"uptime", "Device uptime in seconds", append(l, "type"), nil)
+	versionDesc = collector.NewDesc(prefix+"version", "Running OS version", append(l, "version"))
+	uptimeDesc = collector.NewDesc(prefix+"uptime", "Device uptime in seconds", append(l, "type"))
 
-	memoryTotalDesc = prometheus.NewDesc(prefix+"memory_total", "Total memory", append(l, "type"), nil)
-	memoryUsedDesc = prometheus.NewDesc(prefix+"memory_used", "Used memory", append(l, "type"), nil)
-	memoryFreeDesc = prometheus.NewDesc(prefix+"memory_free", "Free memory", append(l, "type"), nil)
+	memoryTotalDesc = collector.NewDesc(prefix+"memory_total", "Total memory", append(l, "type"))
+	memoryUsedDesc = collector.NewDesc(prefix+"memory_used", "Used memory", append(l, "type"))
+	memoryFreeDesc = collector.NewDesc(prefix+"memory_free", "Free memory", append(l, "type"))
 
-	cpuUsedDesc = prometheus.NewDesc(prefix+"cpu_used_percent", "Percent CPU Used", append(l, "type"), nil)
-	cpuIdleDesc = prometheus.NewDesc(prefix+"cpu_idle_percent", "Percent CPU Idle", append(l, "type"), nil)
+	cpuUsedDesc = collector.NewDesc(prefix+"cpu_used_percent", "Percent CPU Used", append(l, "type"))
+	cpuIdleDesc = collector.NewDesc(prefix+"cpu_idle_percent", "Percent CPU Idle", append(l, "type"))
 }
 
 type systemCollector struct {
+	parsers *collector.Parsers
 }
 
 // NewCollector creates a new collector
 func NewCollector() collector.RPCCollector {
-	return &systemCollector{}
+	c := &systemCollector{
+		parsers: collector.NewParsers("system"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *systemCollector) registerParsers() {
+	commands := map[string]map[string]string{
+		"version": {
+			rpc.ArubaInstant:    "show version",
+			rpc.ArubaController: "show version",
+			rpc.ArubaSwitch:     "show version",
+			rpc.ArubaCXSwitch:   "show version",
+		},
+		"uptime": {
+			rpc.ArubaInstant:    "show version",
+			rpc.ArubaController: "show version",
+			rpc.ArubaSwitch:     "show uptime",
+			rpc.ArubaCXSwitch:   "show uptime",
+		},
+		"memory": {
+			rpc.ArubaInstant:    "show memory",
+			rpc.ArubaController: "show memory",
+			rpc.ArubaSwitch:     "display memory",
+			rpc.ArubaCXSwitch:   "top memory",
+		},
+		"cpu": {
+			rpc.ArubaInstant:    "show cpu",
+			rpc.ArubaController: "show cpuload per-cpu",
+			rpc.ArubaSwitch:     "show cpu",
+			rpc.ArubaCXSwitch:   "show system",
+		},
+	}
+
+	for name, byOS := range commands {
+		for osType, cmd := range byOS {
+			c.parsers.Register(name, &collector.Parser{
+				OSType:   osType,
+				Commands: []string{cmd},
+				Parse:    c.parseFunc(name, osType),
+			})
+		}
+	}
+}
+
+func (c *systemCollector) parseFunc(name, osType string) func(string) (interface{}, error) {
+	switch name {
+	case "version":
+		return func(out string) (interface{}, error) { return c.ParseVersion(osType, out) }
+	case "uptime":
+		return func(out string) (interface{}, error) { return c.ParseUptime(osType, out) }
+	case "memory":
+		return func(out string) (interface{}, error) { return c.ParseMemory(osType, out) }
+	default:
+		return func(out string) (interface{}, error) { return c.ParseCPU(osType, out) }
+	}
+}
+
+// Parsers gets the parsers of the collector
+func (c *systemCollector) Parsers() *collector.Parsers {
+	return c.parsers
 }
 
 // Name returns the name of the collector
@@ -61,14 +126,11 @@ func (*systemCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // CollectVersion collects version informations from Aruba Devices
 func (c *systemCollector) CollectVersion(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	out, err := client.RunCommand([]string{"show version"})
-	if err != nil {
-		return err
-	}
-	item, err := c.ParseVersion(client.OSType, out)
+	parsed, err := collector.Run(c.parsers, "version", client, ch, labelValues)
 	if err != nil {
 		return err
 	}
+	item := parsed.(SystemVersion)
 	l := append(labelValues, item.Version)
 	ch <- prometheus.MustNewConstMetric(versionDesc, prometheus.GaugeValue, 1, l...)
 	return nil
@@ -76,31 +138,11 @@ func (c *systemCollector) CollectVersion(client *rpc.Client, ch chan<- prometheu
 
 // CollectUptime collects uptime informations from Aruba Devices
 func (c *systemCollector) CollectUptime(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	var (
-		out string
-		err error
-	)
-	switch client.OSType {
-	case "ArubaSwitch":
-		out, err = client.RunCommand([]string{"show uptime"})
-		if err != nil {
-			return err
-		}
-	case "ArubaCXSwitch":
-		out, err = client.RunCommand([]string{"show uptime"})
-		if err != nil {
-			return err
-		}
-	default:
-		out, err = client.RunCommand([]string{"show version"})
-		if err != nil {
-			return err
-		}
-	}
-	item, err := c.ParseUptime(client.OSType, out)
+	parsed, err := collector.Run(c.parsers, "uptime", client, ch, labelValues)
 	if err != nil {
 		return err
 	}
+	item := parsed.(SystemUptime)
 	l := append(labelValues, item.Type)
 	ch <- prometheus.MustNewConstMetric(uptimeDesc, prometheus.GaugeValue, item.Uptime, l...)
 	return nil
@@ -108,31 +150,11 @@ func (c *systemCollector) CollectUptime(client *rpc.Client, ch chan<- prometheus
 
 // CollectMemory collects memory informations from Aruba Devices
 func (c *systemCollector) CollectMemory(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	var (
-		out string
-		err error
-	)
-	switch client.OSType {
-	case "ArubaSwitch":
-		out, err = client.RunCommand([]string{"display memory"})
-		if err != nil {
-			return err
-		}
-	case "ArubaCXSwitch":
-		out, err = client.RunCommand([]string{"top memory"})
-		if err != nil {
-			return err
-		}
-	default:
-		out, err = client.RunCommand([]string{"show memory"})
-		if err != nil {
-			return err
-		}
-	}
-	items, err := c.ParseMemory(client.OSType, out)
+	parsed, err := collector.Run(c.parsers, "memory", client, ch, labelValues)
 	if err != nil {
 		return err
 	}
+	items := parsed.([]SystemMemory)
 	for _, item := range items {
 		l := append(labelValues, item.Type)
 		ch <- prometheus.MustNewConstMetric(memoryTotalDesc, prometheus.GaugeValue, item.Total, l...)
@@ -144,31 +166,11 @@ func (c *systemCollector) CollectMemory(client *rpc.Client, ch chan<- prometheus
 
 // CollectCPU collects cpu informations from Aruba Devices
 func (c *systemCollector) CollectCPU(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	var (
-		out string
-		err error
-	)
-	switch client.OSType {
-	case "ArubaController":
-		out, err = client.RunCommand([]string{"show cpuload per-cpu"})
-		if err != nil {
-			return err
-		}
-	case "ArubaCXSwitch":
-		out, err = client.RunCommand([]string{"show system"})
-		if err != nil {
-			return err
-		}
-	default:
-		out, err = client.RunCommand([]string{"show cpu"})
-		if err != nil {
-			return err
-		}
-	}
-	items, err := c.ParseCPU(client.OSType, out)
+	parsed, err := collector.Run(c.parsers, "cpu", client, ch, labelValues)
 	if err != nil {
 		return err
 	}
+	items := parsed.([]SystemCPU)
 	for _, item := range items {
 		l := append(labelValues, item.Type)
 		ch <- prometheus.MustNewConstMetric(cpuUsedDesc, prometheus.GaugeValue, item.Used, l...)
@@ -0,0 +1,192 @@
+package textfsm
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ListSeparator joins the items of List values in records
+const ListSeparator = ","
+
+// Record holds the values extracted for one record by value name
+type Record map[string]string
+
+type fsm struct {
+	t       *Template
+	values  map[string][]string
+	set     map[string]bool
+	records [][]string
+}
+
+// ParseText runs the template against the text and returns the extracted records
+func (t *Template) ParseText(text string) ([]Record, error) {
+	rows, err := t.ParseTextToRows(text)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(rows))
+	for i, row := range rows {
+		r := Record{}
+		for j, v := range t.Values {
+			r[v.Name] = row[j]
+		}
+		records[i] = r
+	}
+
+	return records, nil
+}
+
+// ParseTextToRows runs the template against the text and returns the extracted
+// records as rows with one column per value in the order of Header
+func (t *Template) ParseTextToRows(text string) ([][]string, error) {
+	f := &fsm{
+		t:      t,
+		values: make(map[string][]string),
+		set:    make(map[string]bool),
+	}
+
+	cur := t.states["Start"]
+lines:
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		for _, r := range cur.rules {
+			m := r.regex.FindStringSubmatchIndex(line)
+			if m == nil {
+				continue
+			}
+
+			for i, name := range r.regex.SubexpNames() {
+				if name == "" {
+					continue
+				}
+				if m[2*i] < 0 {
+					f.unset(name)
+					continue
+				}
+				f.assign(name, line[m[2*i]:m[2*i+1]])
+			}
+
+			if r.lineOp == opError {
+				msg := r.message
+				if msg == "" {
+					msg = "state error raised"
+				}
+				return nil, errors.Errorf("%s (rule at line %d, input %q)", msg, r.line, line)
+			}
+
+			switch r.recordOp {
+			case opRecord:
+				f.record()
+			case opClear:
+				f.clear(false)
+			case opClearall:
+				f.clear(true)
+			}
+
+			if r.newState == "End" {
+				return f.records, nil
+			}
+			if r.newState == "EOF" {
+				break lines
+			}
+			if r.newState != "" {
+				cur = t.states[r.newState]
+			}
+
+			if r.lineOp != opContinue {
+				break
+			}
+		}
+	}
+
+	// the implicit EOF state records the last values, unless an empty EOF state is defined
+	if _, found := t.states["EOF"]; !found {
+		f.record()
+	}
+
+	return f.records, nil
+}
+
+func (f *fsm) assign(name, value string) {
+	v := f.t.value(name)
+
+	if v.List {
+		f.values[name] = append(f.values[name], value)
+	} else {
+		f.values[name] = []string{value}
+	}
+	f.set[name] = true
+
+	if v.Fillup && value != "" {
+		idx := f.index(name)
+		for i := len(f.records) - 1; i >= 0; i-- {
+			if f.records[i][idx] != "" {
+				break
+			}
+			f.records[i][idx] = value
+		}
+	}
+}
+
+func (f *fsm) unset(name string) {
+	if f.t.value(name).List {
+		return
+	}
+
+	delete(f.values, name)
+	delete(f.set, name)
+}
+
+func (f *fsm) record() {
+	row := make([]string, len(f.t.Values))
+	empty := true
+	for i, v := range f.t.Values {
+		if v.Required && !f.hasValue(v.Name) {
+			f.clear(false)
+			return
+		}
+		if f.set[v.Name] {
+			empty = false
+		}
+		row[i] = strings.Join(f.values[v.Name], ListSeparator)
+	}
+
+	if !empty {
+		f.records = append(f.records, row)
+	}
+	f.clear(false)
+}
+
+func (f *fsm) hasValue(name string) bool {
+	for _, s := range f.values[name] {
+		if s != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clear resets all values except Filldown values, or all values if all is set
+func (f *fsm) clear(all bool) {
+	for _, v := range f.t.Values {
+		if v.Filldown && !all {
+			continue
+		}
+		delete(f.values, v.Name)
+		delete(f.set, v.Name)
+	}
+}
+
+func (f *fsm) index(name string) int {
+	for i, v := range f.t.Values {
+		if v.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
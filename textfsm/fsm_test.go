@@ -0,0 +1,344 @@
+package textfsm_test
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/textfsm"
+)
+
+type fsmTest struct {
+	name     string
+	template string
+	text     string
+	want     [][]string
+}
+
+func runFSMTests(t *testing.T, tests []fsmTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		tmpl, err := textfsm.Parse(strings.NewReader(test.template))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		got, err := tmpl.ParseTextToRows(test.text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if got == nil {
+			got = [][]string{}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestValueOptions(t *testing.T) {
+	runFSMTests(t, []fsmTest{
+		{
+			name: "Filldown",
+			template: `Value Filldown VLAN (\d+)
+Value PORT (\S+)
+
+Start
+  ^VLAN ${VLAN}
+  ^  port ${PORT} -> Record
+`,
+			text: "VLAN 1\n  port 1/1\n  port 1/2\nVLAN 2\n  port 1/3\n",
+			want: [][]string{{"1", "1/1"}, {"1", "1/2"}, {"2", "1/3"}, {"2", ""}},
+		},
+		{
+			name: "Filldown and Required",
+			template: `Value Filldown VLAN (\d+)
+Value Required PORT (\S+)
+
+Start
+  ^VLAN ${VLAN}
+  ^  port ${PORT} -> Record
+`,
+			text: "VLAN 1\n  port 1/1\n  port 1/2\nVLAN 2\n  port 1/3\n",
+			want: [][]string{{"1", "1/1"}, {"1", "1/2"}, {"2", "1/3"}},
+		},
+		{
+			name: "without Fillup",
+			template: `Value A (\d+)
+Value B (\w+)
+
+Start
+  ^a ${A} -> Record
+  ^b ${B}
+`,
+			text: "a 1\na 2\nb x\na 3\n",
+			want: [][]string{{"1", ""}, {"2", ""}, {"3", "x"}},
+		},
+		{
+			name: "Fillup",
+			template: `Value A (\d+)
+Value Fillup B (\w+)
+
+Start
+  ^a ${A} -> Record
+  ^b ${B}
+`,
+			text: "a 1\na 2\nb x\na 3\n",
+			want: [][]string{{"1", "x"}, {"2", "x"}, {"3", "x"}},
+		},
+		{
+			name: "Fillup stops at a set value",
+			template: `Value A (\d+)
+Value Fillup B (\w+)
+
+Start
+  ^a ${A} -> Record
+  ^b ${B} -> Record
+`,
+			text: "a 1\nb x\na 2\na 3\nb y\n",
+			want: [][]string{{"1", "x"}, {"", "x"}, {"2", "y"}, {"3", "y"}, {"", "y"}},
+		},
+		{
+			name: "Required",
+			template: `Value Required NAME (\S+)
+Value DESC (.*)
+
+Start
+  ^name ${NAME}
+  ^desc ${DESC}
+  ^$$ -> Record
+`,
+			text: "name a\ndesc first\n\ndesc orphan\n\nname b\n",
+			want: [][]string{{"a", "first"}, {"b", ""}},
+		},
+		{
+			name: "List",
+			template: `Value NAME (\S+)
+Value List MEMBERS (\S+)
+
+Start
+  ^lag ${NAME}
+  ^  member ${MEMBERS}
+  ^end -> Record
+`,
+			text: "lag 1\n  member 1/1\n  member 1/2\nend\nlag 2\nend\n",
+			want: [][]string{{"1", "1/1,1/2"}, {"2", ""}},
+		},
+		{
+			name: "unmatched optional group unsets the value",
+			template: `Value NAME (\S+)
+Value DESC (\S+)
+
+Start
+  ^name ${NAME}(\s+${DESC})?
+  ^end -> Record
+`,
+			text: "name a x\nname b\nend\n",
+			want: [][]string{{"b", ""}},
+		},
+	})
+}
+
+func TestActions(t *testing.T) {
+	runFSMTests(t, []fsmTest{
+		{
+			name: "Clear and Clearall",
+			template: `Value Filldown VLAN (\d+)
+Value PORT (\S+)
+
+Start
+  ^VLAN ${VLAN}
+  ^port ${PORT} -> Record
+  ^resetall -> Clearall
+  ^reset -> Clear
+`,
+			text: "VLAN 1\nport a\nreset\nport b\nresetall\nport c\n",
+			want: [][]string{{"1", "a"}, {"1", "b"}, {"", "c"}},
+		},
+		{
+			name: "Clear keeps Filldown values",
+			template: `Value Filldown VLAN (\d+)
+Value PORT (\S+)
+
+Start
+  ^VLAN ${VLAN}
+  ^port ${PORT}
+  ^reset -> Clear
+  ^save -> Record
+`,
+			text: "VLAN 1\nport a\nreset\nsave\n",
+			want: [][]string{{"1", ""}, {"1", ""}},
+		},
+		{
+			name: "NoRecord",
+			template: `Value NAME (\S+)
+
+Start
+  ^name ${NAME} -> NoRecord
+`,
+			text: "name a\nname b\n",
+			want: [][]string{{"b"}},
+		},
+		{
+			name: "Continue",
+			template: `Value A (\w+)
+Value B (\w+)
+
+Start
+  ^${A} -> Continue
+  ^\w+ ${B} -> Record
+`,
+			text: "x y\nz w\n",
+			want: [][]string{{"x", "y"}, {"z", "w"}},
+		},
+		{
+			name: "Continue.Record",
+			template: `Value A (\w+)
+Value B (\w+)
+
+Start
+  ^${A} -> Continue.Record
+  ^\w+ ${B}
+`,
+			text: "x y\nz w\n",
+			want: [][]string{{"x", ""}, {"z", "y"}, {"", "w"}},
+		},
+		{
+			name: "Next.Record and state change",
+			template: `Value NAME (\S+)
+Value STATE (\S+)
+
+Start
+  ^Interfaces -> Interfaces
+
+Interfaces
+  ^${NAME}\s+${STATE} -> Next.Record Interfaces
+  ^$$ -> Start
+`,
+			text: "skipped line\nInterfaces\n1/1 up\n1/2 down\n\n1/3 up\n",
+			want: [][]string{{"1/1", "up"}, {"1/2", "down"}},
+		},
+		{
+			name: "Record with state change",
+			template: `Value NAME (\S+)
+
+Start
+  ^name ${NAME} -> Record Names
+
+Names
+  ^name ${NAME} -> Record
+`,
+			text: "name a\nname b\n",
+			want: [][]string{{"a"}, {"b"}},
+		},
+		{
+			name: "End",
+			template: `Value NAME (\S+)
+
+Start
+  ^name ${NAME}
+  ^stop -> End
+`,
+			text: "name a\nstop\nname b\n",
+			want: [][]string{},
+		},
+		{
+			name: "EOF action",
+			template: `Value NAME (\S+)
+
+Start
+  ^name ${NAME}
+  ^stop -> EOF
+`,
+			text: "name a\nstop\nname b\n",
+			want: [][]string{{"a"}},
+		},
+		{
+			name: "implicit EOF records the last values",
+			template: `Value NAME (\S+)
+
+Start
+  ^name ${NAME}
+`,
+			text: "name a\n",
+			want: [][]string{{"a"}},
+		},
+		{
+			name: "empty EOF state",
+			template: `Value NAME (\S+)
+
+Start
+  ^name ${NAME}
+
+EOF
+`,
+			text: "name a\n",
+			want: [][]string{},
+		},
+	})
+}
+
+func TestErrorAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		err    string
+	}{
+		{name: "message", action: `Error "unexpected line"`, err: `unexpected line (rule at line 5, input "bad")`},
+		{name: "no message", action: "Error", err: `state error raised (rule at line 5, input "bad")`},
+	}
+
+	for _, test := range tests {
+		tmpl, err := textfsm.Parse(strings.NewReader("Value NAME (\\S+)\n\nStart\n  ^name ${NAME} -> Record\n  ^. -> " + test.action + "\n"))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		_, err = tmpl.ParseText("name a\nbad\nname b\n")
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := textfsm.ParseFile("testdata/aruba_switch_show_trunks.textfsm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile("../samples/lag/ArubaSwitch/show trunks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := tmpl.ParseText(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := func(port, name, typ, group, trunkType string) textfsm.Record {
+		return textfsm.Record{"LOAD_BALANCING": "L3-based", "PORT": port, "NAME": name, "TYPE": typ, "GROUP": group, "TRUNK_TYPE": trunkType}
+	}
+	want := []textfsm.Record{
+		row("25", "uplink-1", "SFP+SR", "Trk1", "LACP"),
+		row("26", "uplink-2", "SFP+SR", "Trk1", "LACP"),
+		row("47", "", "100/1000T", "Trk2", "LACP"),
+		row("48", "", "100/1000T", "Trk2", "LACP"),
+		row("49", "server-1", "100/1000T", "Trk3", "Trunk"),
+		row("50", "server-1", "100/1000T", "Trk3", "Trunk"),
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("got %v, want %v", records, want)
+	}
+
+	_, err = tmpl.ParseText(string(b) + "  Trk1 is down\n")
+	if err == nil {
+		t.Error("expected an error for an unexpected line")
+	}
+}
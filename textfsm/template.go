@@ -0,0 +1,341 @@
+package textfsm
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const maxNameLen = 48
+
+var (
+	nameRegexp    = regexp.MustCompile(`^\w+$`)
+	commentRegexp = regexp.MustCompile(`^\s*#`)
+	ruleRegexp    = regexp.MustCompile(`^(?: {1,2}|\t)\^`)
+	actionRegexp  = regexp.MustCompile(`^(.*)\s->(.*)$`)
+	opRegexp      = regexp.MustCompile(`^(?:(Next|Continue)(?:\.(Record|NoRecord|Clear|Clearall))?|(Record|NoRecord|Clear|Clearall))$`)
+	varRegexp     = regexp.MustCompile(`\$\$|\$\{(\w+)\}|\$(\w+)`)
+)
+
+// Template is a parsed TextFSM template
+type Template struct {
+	Values []*Value
+	states map[string]*state
+}
+
+// Value is a column extracted by a template
+type Value struct {
+	Name     string
+	Regex    string
+	Filldown bool
+	Fillup   bool
+	Key      bool
+	List     bool
+	Required bool
+}
+
+type state struct {
+	name  string
+	rules []*rule
+}
+
+type lineOp int
+
+const (
+	opNext lineOp = iota
+	opContinue
+	opError
+)
+
+type recordOp int
+
+const (
+	opNoRecord recordOp = iota
+	opRecord
+	opClear
+	opClearall
+)
+
+type rule struct {
+	line     int
+	match    string
+	regex    *regexp.Regexp
+	lineOp   lineOp
+	recordOp recordOp
+	newState string
+	message  string
+}
+
+// ParseFile reads a template from a file
+func ParseFile(path string) (*Template, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t, err := Parse(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse template %s", path)
+	}
+
+	return t, nil
+}
+
+// Parse reads a template. The syntax is the one of the Python TextFSM module,
+// except that regular expressions use the Go (RE2) syntax.
+func Parse(r io.Reader) (*Template, error) {
+	t := &Template{states: make(map[string]*state)}
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	next := func() (string, bool) {
+		for scanner.Scan() {
+			lineNum++
+			line := strings.TrimRight(scanner.Text(), "\r")
+			if commentRegexp.MatchString(line) {
+				continue
+			}
+			return line, true
+		}
+		return "", false
+	}
+
+	// values are defined at the start of the template up to the first blank line
+	for {
+		line, ok := next()
+		if !ok || strings.TrimSpace(line) == "" {
+			break
+		}
+		if !strings.HasPrefix(line, "Value ") {
+			return nil, errors.Errorf("line %d: expected a value definition, got %q", lineNum, line)
+		}
+		v, err := parseValue(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lineNum)
+		}
+		if t.value(v.Name) != nil {
+			return nil, errors.Errorf("line %d: duplicate value %s", lineNum, v.Name)
+		}
+		t.Values = append(t.Values, v)
+	}
+	if len(t.Values) == 0 {
+		return nil, errors.New("no values defined")
+	}
+
+	var cur *state
+	order := []*state{}
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			cur = nil
+		case cur == nil:
+			name := strings.TrimSpace(line)
+			if !nameRegexp.MatchString(name) || len(name) > maxNameLen {
+				return nil, errors.Errorf("line %d: invalid state name %q", lineNum, name)
+			}
+			if _, found := t.states[name]; found {
+				return nil, errors.Errorf("line %d: duplicate state %s", lineNum, name)
+			}
+			cur = &state{name: name}
+			t.states[name] = cur
+			order = append(order, cur)
+		default:
+			if !ruleRegexp.MatchString(line) {
+				return nil, errors.Errorf("line %d: expected a rule starting with ^, got %q", lineNum, line)
+			}
+			r, err := t.parseRule(strings.TrimSpace(line))
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", lineNum)
+			}
+			r.line = lineNum
+			cur.rules = append(cur.rules, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, t.validateStates(order)
+}
+
+// Header gets the names of the values in the order they are defined
+func (t *Template) Header() []string {
+	header := make([]string, len(t.Values))
+	for i, v := range t.Values {
+		header[i] = v.Name
+	}
+
+	return header
+}
+
+func (t *Template) value(name string) *Value {
+	for _, v := range t.Values {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func parseValue(line string) (*Value, error) {
+	tokens := strings.Split(line, " ")
+	if len(tokens) < 3 {
+		return nil, errors.New("expected at least 3 tokens in value definition")
+	}
+
+	v := &Value{}
+	if strings.HasPrefix(tokens[2], "(") {
+		v.Name = tokens[1]
+		v.Regex = strings.Join(tokens[2:], " ")
+	} else {
+		for _, option := range strings.Split(tokens[1], ",") {
+			switch option {
+			case "Filldown":
+				v.Filldown = true
+			case "Fillup":
+				v.Fillup = true
+			case "Key":
+				v.Key = true
+			case "List":
+				v.List = true
+			case "Required":
+				v.Required = true
+			default:
+				return nil, errors.Errorf("unknown value option %q", option)
+			}
+		}
+		v.Name = tokens[2]
+		v.Regex = strings.Join(tokens[3:], " ")
+	}
+
+	if !nameRegexp.MatchString(v.Name) || len(v.Name) > maxNameLen {
+		return nil, errors.Errorf("invalid value name %q", v.Name)
+	}
+	if !strings.HasPrefix(v.Regex, "(") || !strings.HasSuffix(v.Regex, ")") || strings.HasSuffix(v.Regex, `\)`) {
+		return nil, errors.Errorf("regex of value %s must be enclosed in parentheses", v.Name)
+	}
+	_, err := regexp.Compile(v.Regex)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid regex for value %s", v.Name)
+	}
+
+	return v, nil
+}
+
+func (t *Template) parseRule(line string) (*rule, error) {
+	r := &rule{match: line}
+
+	if m := actionRegexp.FindStringSubmatch(line); m != nil {
+		r.match = m[1]
+		err := r.parseAction(strings.TrimSpace(m[2]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	expanded := varRegexp.ReplaceAllStringFunc(r.match, func(s string) string {
+		if s == "$$" {
+			return "$"
+		}
+		name := strings.Trim(s, "${}")
+		v := t.value(name)
+		if v == nil {
+			err = errors.Errorf("unknown value %s in rule", name)
+			return s
+		}
+		return "(?P<" + v.Name + ">" + v.Regex[1:]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.regex, err = regexp.Compile(expanded)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid regex in rule")
+	}
+
+	return r, nil
+}
+
+func (r *rule) parseAction(action string) error {
+	if action == "Error" || strings.HasPrefix(action, "Error ") {
+		r.lineOp = opError
+		r.message = strings.Trim(strings.TrimSpace(strings.TrimPrefix(action, "Error")), `"`)
+		return nil
+	}
+
+	tokens := strings.Fields(action)
+	if len(tokens) == 0 || len(tokens) > 2 {
+		return errors.Errorf("invalid action %q", action)
+	}
+
+	if m := opRegexp.FindStringSubmatch(tokens[0]); m != nil {
+		if m[1] == "Continue" {
+			r.lineOp = opContinue
+		}
+		op := m[2]
+		if op == "" {
+			op = m[3]
+		}
+		switch op {
+		case "Record":
+			r.recordOp = opRecord
+		case "Clear":
+			r.recordOp = opClear
+		case "Clearall":
+			r.recordOp = opClearall
+		}
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 1 {
+		if !nameRegexp.MatchString(tokens[0]) {
+			return errors.Errorf("invalid state name %q in action", tokens[0])
+		}
+		r.newState = tokens[0]
+	} else if len(tokens) > 1 {
+		return errors.Errorf("invalid action %q", action)
+	}
+
+	if r.lineOp == opContinue && r.newState != "" {
+		return errors.New("Continue can not be combined with a state change")
+	}
+
+	return nil
+}
+
+func (t *Template) validateStates(order []*state) error {
+	if _, found := t.states["Start"]; !found {
+		return errors.New("missing Start state")
+	}
+	for _, name := range []string{"End", "EOF"} {
+		if s, found := t.states[name]; found && len(s.rules) > 0 {
+			return errors.Errorf("state %s is reserved and must be empty", name)
+		}
+	}
+
+	for _, s := range order {
+		for _, r := range s.rules {
+			if r.newState == "" || r.newState == "End" || r.newState == "EOF" {
+				continue
+			}
+			if _, found := t.states[r.newState]; !found {
+				return errors.Errorf("line %d: unknown state %s", r.line, r.newState)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package textfsm_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/textfsm"
+)
+
+func TestParse(t *testing.T) {
+	tmpl, err := textfsm.Parse(strings.NewReader(`# comment before the values
+Value Filldown,Key VLAN (\d+)
+Value Required PORT (\S+)
+Value Fillup,List NAMES (\w+)
+Value DESC (.*)
+
+Start
+  # comment between rules
+  ^VLAN ${VLAN} -> Ports
+  ^\s*$$
+
+Ports
+  ^\s+${PORT} ${NAMES} -> Continue
+  ^\s+\S+ \w+ ${DESC} -> Next.Record
+  ^end -> Start
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tmpl.Header(); !reflect.DeepEqual(got, []string{"VLAN", "PORT", "NAMES", "DESC"}) {
+		t.Errorf("got header %v", got)
+	}
+
+	want := []*textfsm.Value{
+		{Name: "VLAN", Regex: `(\d+)`, Filldown: true, Key: true},
+		{Name: "PORT", Regex: `(\S+)`, Required: true},
+		{Name: "NAMES", Regex: `(\w+)`, Fillup: true, List: true},
+		{Name: "DESC", Regex: `(.*)`},
+	}
+	if !reflect.DeepEqual(tmpl.Values, want) {
+		for i := range tmpl.Values {
+			t.Errorf("got value %+v", tmpl.Values[i])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		err      string
+	}{
+		{name: "no values", template: "\nStart\n  ^x\n", err: "no values defined"},
+		{name: "rules without values", template: "Start\n  ^x\n", err: `line 1: expected a value definition, got "Start"`},
+		{name: "too few tokens", template: "Value X\n\nStart\n", err: "line 1: expected at least 3 tokens"},
+		{name: "unknown option", template: "Value Optional X (\\d+)\n\nStart\n", err: `line 1: unknown value option "Optional"`},
+		{name: "regex without parentheses", template: "Value X (\\d+\n\nStart\n", err: "line 1: regex of value X must be enclosed in parentheses"},
+		{name: "invalid value regex", template: "Value X ((\\d+)\n\nStart\n", err: "line 1: invalid regex for value X"},
+		{name: "invalid value name", template: "Value X-Y (\\d+)\n\nStart\n", err: `line 1: invalid value name "X-Y"`},
+		{name: "duplicate value", template: "Value X (\\d+)\nValue X (\\w+)\n\nStart\n", err: "line 2: duplicate value X"},
+		{name: "missing start", template: "Value X (\\d+)\n\nOther\n  ^${X}\n", err: "missing Start state"},
+		{name: "duplicate state", template: "Value X (\\d+)\n\nStart\n  ^${X}\n\nStart\n", err: "line 6: duplicate state Start"},
+		{name: "invalid state name", template: "Value X (\\d+)\n\nStart here\n", err: `line 3: invalid state name "Start here"`},
+		{name: "rule without caret", template: "Value X (\\d+)\n\nStart\n  ${X}\n", err: "line 4: expected a rule starting with ^"},
+		{name: "unknown value in rule", template: "Value X (\\d+)\n\nStart\n  ^${Y}\n", err: "line 4: unknown value Y in rule"},
+		{name: "invalid rule regex", template: "Value X (\\d+)\n\nStart\n  ^${X}(\n", err: "line 4: invalid regex in rule"},
+		{name: "unknown state in action", template: "Value X (\\d+)\n\nStart\n  ^${X} -> Record Other\n", err: "line 4: unknown state Other"},
+		{name: "invalid action", template: "Value X (\\d+)\n\nStart\n  ^${X} -> Record Start Other\n", err: `line 4: invalid action "Record Start Other"`},
+		{name: "continue with state change", template: "Value X (\\d+)\n\nStart\n  ^${X} -> Continue Start\n", err: "line 4: Continue can not be combined with a state change"},
+		{name: "rules in EOF", template: "Value X (\\d+)\n\nStart\n  ^${X}\n\nEOF\n  ^x\n", err: "state EOF is reserved and must be empty"},
+		{name: "rules in End", template: "Value X (\\d+)\n\nStart\n  ^${X}\n\nEnd\n  ^x\n", err: "state End is reserved and must be empty"},
+	}
+
+	for _, test := range tests {
+		_, err := textfsm.Parse(strings.NewReader(test.template))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	tmpl, err := textfsm.ParseFile("testdata/aruba_switch_show_trunks.textfsm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(tmpl.Values); got != 6 {
+		t.Errorf("got %d values, want 6", got)
+	}
+
+	_, err = textfsm.ParseFile("testdata/missing.textfsm")
+	if err == nil {
+		t.Error("expected an error for a missing template")
+	}
+}
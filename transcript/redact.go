@@ -0,0 +1,75 @@
+package transcript
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Redacted replaces secrets in the transcripts
+const Redacted = "<redacted>"
+
+// Redactor removes secrets from the output of a command before it is written
+type Redactor func(cmd, output string) string
+
+// DefaultPatterns match the secrets commonly found in the outputs of Aruba devices
+// (e.g. in the running config). They are always applied by the redactors created by RegexRedactor.
+var DefaultPatterns = []string{
+	`(?im)\bpassword\s+(?:manager|operator)\s+user-name\s+\S+\s+\S+\s+(\S+)`,
+	`(?im)\b(?:password|passwd|passphrase|secret|key|psk|wpa-passphrase)\b(?:\s+(?:plaintext|ciphertext|encrypted|sha1|sha256|md5|des|aes|[0-9]))*\s+(\S+)`,
+	`(?im)\bcommunity\s+("[^"]*"|\S+)`,
+}
+
+// RegexRedactor creates a redactor which replaces the matches of regular expressions with Redacted.
+// If an expression has capture groups, only the captured text is replaced, so the keywords are kept.
+func RegexRedactor(patterns []string) (Redactor, error) {
+	res := make([]*regexp.Regexp, 0, len(DefaultPatterns)+len(patterns))
+	for _, p := range append(append([]string{}, DefaultPatterns...), patterns...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+
+	return func(cmd, output string) string {
+		for _, re := range res {
+			output = redact(re, output)
+		}
+
+		return output
+	}, nil
+}
+
+func redact(re *regexp.Regexp, s string) string {
+	type span struct{ start, end int }
+	spans := []span{}
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		if len(m) == 2 {
+			spans = append(spans, span{m[0], m[1]})
+			continue
+		}
+		for i := 2; i+1 < len(m); i += 2 {
+			if m[i] >= 0 && m[i] < m[i+1] {
+				spans = append(spans, span{m[i], m[i+1]})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	out := make([]byte, 0, len(s))
+	last := 0
+	for _, sp := range spans {
+		// nested groups are replaced by their outermost group
+		if sp.start < last {
+			continue
+		}
+		out = append(out, s[last:sp.start]...)
+		out = append(out, Redacted...)
+		last = sp.end
+	}
+
+	return string(append(out, s[last:]...))
+}
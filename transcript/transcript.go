@@ -0,0 +1,213 @@
+// Package transcript records the commands sent to devices and the outputs received, so parser
+// failures can be reproduced offline and new samples can be contributed. The transcripts are
+// written to <directory>/<host>/<collector>/<OS type>/<command>, so the directory of a host has
+// the layout of the samples directory.
+package transcript
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultKeep is the number of previous transcripts kept per command if none is configured
+const DefaultKeep = 3
+
+// ConnectCollector is the collector name used for the commands run before any collector (e.g. to disable paging)
+const ConnectCollector = "connect"
+
+// UnknownOSType is the OS type directory of the commands run before the OS type of the device is known
+const UnknownOSType = "unknown"
+
+// promptRegexp matches the prompts of all OS types, e.g. "switch1# " or "(controller1) #"
+var promptRegexp = regexp.MustCompile(`^\(?[\w.-]+\)?\s?(?:\([^)]*\))?\s?[#>]$`)
+
+// files serializes the rotation of transcript files, the same host can be scraped concurrently
+var files sync.Mutex
+
+// Recorder writes the transcripts of a session with a device
+type Recorder struct {
+	dir    string
+	keep   int
+	redact Redactor
+
+	mu        sync.Mutex
+	collector string
+	osType    string
+}
+
+// New creates a recorder for a host. keep is the number of previous transcripts kept per command,
+// redact is applied to every output before it is written and may be nil.
+func New(directory, host string, keep int, redact Redactor) *Recorder {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+
+	return &Recorder{
+		dir:       filepath.Join(directory, fileName(host)),
+		keep:      keep,
+		redact:    redact,
+		collector: ConnectCollector,
+		osType:    UnknownOSType,
+	}
+}
+
+// SetCollector sets the collector the following commands are recorded for.
+// It can be called on a nil recorder, which does nothing.
+func (r *Recorder) SetCollector(name string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collector = name
+}
+
+// SetOSType sets the OS type of the device once it is known.
+// It can be called on a nil recorder, which does nothing.
+func (r *Recorder) SetOSType(osType string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.osType = osType
+}
+
+// Record writes the output of commands run together, each command to its own file. Errors are only
+// logged, since recording must not break a scrape. It can be called on a nil recorder, which does nothing.
+func (r *Recorder) Record(cmds []string, output string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	dir := filepath.Join(r.dir, fileName(r.collector), fileName(r.osType))
+	r.mu.Unlock()
+
+	names, outputs := split(cmds, output)
+	if len(names) == 0 {
+		return
+	}
+
+	files.Lock()
+	defer files.Unlock()
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		log.Warnf("could not record transcript: %v", err)
+		return
+	}
+
+	for i, name := range names {
+		out := outputs[i]
+		if r.redact != nil {
+			out = r.redact(name, out)
+		}
+
+		path := filepath.Join(dir, fileName(name))
+		rotate(path, r.keep)
+		err = ioutil.WriteFile(path, []byte(out), 0600)
+		if err != nil {
+			log.Warnf("could not record transcript: %v", err)
+		}
+	}
+}
+
+// split splits the output of commands run together at the echo of each command. If the echos
+// can not be found, the complete output is returned for all commands joined by " + ".
+func split(cmds []string, output string) ([]string, []string) {
+	names := []string{}
+	for _, cmd := range cmds {
+		if strings.TrimSpace(cmd) != "" {
+			names = append(names, cmd)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	lines := strings.Split(output, "\n")
+	starts := make([]int, 0, len(names))
+	from := 0
+	for _, cmd := range names {
+		found := -1
+		for i := from; i < len(lines); i++ {
+			if strings.HasSuffix(strings.TrimRight(lines[i], " "), cmd) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return []string{strings.Join(names, " + ")}, []string{output}
+		}
+		starts = append(starts, found)
+		from = found + 1
+	}
+
+	outputs := make([]string, len(names))
+	for i, start := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		outputs[i] = strings.Join(lines[start:end], "\n")
+	}
+
+	return names, outputs
+}
+
+// rotate renames path to path.1, path.1 to path.2 and so on, the oldest transcript is replaced
+func rotate(path string, keep int) {
+	for i := keep; i > 0; i-- {
+		src := path
+		if i > 1 {
+			src = fmt.Sprintf("%s.%d", path, i-1)
+		}
+
+		err := os.Rename(src, fmt.Sprintf("%s.%d", path, i))
+		if err != nil && !os.IsNotExist(err) {
+			log.Warnf("could not rotate transcript: %v", err)
+		}
+	}
+}
+
+// fileName makes a host, collector or command usable as a file name
+func fileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_", "\x00", "").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+
+	return name
+}
+
+// Clean removes the echo of the command and the prompt following the output from a transcript,
+// so it can be used as a sample. name is the file name of the transcript.
+func Clean(name, output string) string {
+	output = strings.ReplaceAll(output, "\r", "")
+	if i := strings.Index(output, "\n"); i >= 0 && strings.HasSuffix(fileName(output[:i]), name) {
+		output = output[i+1:]
+	}
+
+	lines := strings.Split(output, "\n")
+	end := len(lines)
+	for end > 0 && (strings.TrimSpace(lines[end-1]) == "" || promptRegexp.MatchString(strings.TrimSpace(lines[end-1]))) {
+		end--
+	}
+	if end < len(lines) && promptRegexp.MatchString(strings.TrimSpace(strings.Join(lines[end:], ""))) {
+		return strings.Join(lines[:end], "\n") + "\n"
+	}
+
+	return output
+}
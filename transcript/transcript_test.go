@@ -0,0 +1,170 @@
+package transcript_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/transcript"
+)
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestRecordSplitsCommands(t *testing.T) {
+	dir := t.TempDir()
+	r := transcript.New(dir, "switch1", 0, nil)
+	r.SetCollector("interfaces")
+
+	r.Record([]string{"show interface", "show interface counters"}, "(ctrl) #show interface\nGE 0/0/0 is up\n(ctrl) #show interface counters\nGE0/0/0 100\n(ctrl) #")
+
+	path := filepath.Join(dir, "switch1", "interfaces", transcript.UnknownOSType)
+	if got := read(t, filepath.Join(path, "show interface")); got != "(ctrl) #show interface\nGE 0/0/0 is up" {
+		t.Errorf("got %q", got)
+	}
+	if got := read(t, filepath.Join(path, "show interface counters")); got != "(ctrl) #show interface counters\nGE0/0/0 100\n(ctrl) #" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRecordWithoutEcho(t *testing.T) {
+	dir := t.TempDir()
+	r := transcript.New(dir, "switch1", 0, nil)
+
+	r.Record([]string{"show a", "show b"}, "output")
+	r.Record([]string{""}, "switch# ")
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "switch1", transcript.ConnectCollector, transcript.UnknownOSType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "show a + show b" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
+
+func TestRecordRotates(t *testing.T) {
+	dir := t.TempDir()
+	r := transcript.New(dir, "[2001:db8::1]:22", 2, nil)
+	r.SetCollector("system")
+	r.SetOSType("ArubaCXSwitch")
+
+	for _, out := range []string{"1", "2", "3", "4"} {
+		r.Record([]string{"show version"}, "show version\n"+out)
+	}
+
+	path := filepath.Join(dir, "[2001:db8::1]:22", "system", "ArubaCXSwitch", "show version")
+	for suffix, want := range map[string]string{"": "4", ".1": "3", ".2": "2"} {
+		if got := read(t, path+suffix); !strings.HasSuffix(got, want) {
+			t.Errorf("%s: got %q, want output %s", path+suffix, got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more transcripts than configured were kept")
+	}
+}
+
+func TestRecordSanitizesNames(t *testing.T) {
+	dir := t.TempDir()
+	r := transcript.New(dir, "switch1", 0, nil)
+	r.SetCollector("interfaces")
+
+	r.Record([]string{"show interface 1/1/1"}, "show interface 1/1/1\nup")
+
+	_, err := os.Stat(filepath.Join(dir, "switch1", "interfaces", transcript.UnknownOSType, "show interface 1_1_1"))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *transcript.Recorder
+	r.SetCollector("system")
+	r.SetOSType("ArubaCXSwitch")
+	r.Record([]string{"show version"}, "output")
+}
+
+func TestRecordSamplesLayout(t *testing.T) {
+	dir := t.TempDir()
+	r := transcript.New(dir, "switch1", 0, nil)
+	r.SetCollector("identify")
+	r.Record([]string{"show version"}, "switch1# show version\nArubaOS-CX\nswitch1# ")
+
+	r.SetOSType("ArubaCXSwitch")
+	r.SetCollector("system")
+	r.Record([]string{"show version"}, "switch1# show version\nArubaOS-CX\nswitch1# ")
+
+	paths, err := filepath.Glob(filepath.Join(dir, "switch1", "*", "*", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "switch1", "identify", transcript.UnknownOSType, "show version"),
+		filepath.Join(dir, "switch1", "system", "ArubaCXSwitch", "show version"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+}
+
+const runningConfig = `hostname "switch1"
+snmp-server community "public" operator
+snmp-server community private
+radius-server host 10.0.0.1 key plaintext S3cr3t!
+tacacs-server host 10.0.0.2 key 7 0822455D0A16
+password manager user-name "admin" sha1 "0123456789abcdef"
+user admin group administrators password ciphertext AQBapalKj
+wlan ssid-profile corp
+  wpa-passphrase hunter22
+interface 1/1/1
+  description uplink
+`
+
+func TestRedact(t *testing.T) {
+	redact, err := transcript.RegexRedactor([]string{`(?m)^hostname\s+(\S+)`, `description (.*)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.AssertText(t, "redacted", redact("show running-config", runningConfig))
+}
+
+func TestRedactInvalidPattern(t *testing.T) {
+	_, err := transcript.RegexRedactor([]string{"("})
+	if err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "show interface", output: "(ctrl) #show interface\nGE 0/0/0 is up", want: "GE 0/0/0 is up"},
+		{name: "show interface counters", output: "(ctrl) #show interface counters\r\nGE0/0/0 100\r\n(ctrl) #", want: "GE0/0/0 100\n"},
+		{name: "show vlan", output: "switch1# show vlan\n\nVLAN 1\n\nswitch1# ", want: "\nVLAN 1\n"},
+		{name: "show cpu", output: "cpu0: user 1%\n", want: "cpu0: user 1%\n"},
+		{name: "show ip route vrf_a", output: "sw1# show ip route vrf/a\n0.0.0.0/0", want: "0.0.0.0/0"},
+	}
+
+	for _, test := range tests {
+		if got := transcript.Clean(test.name, test.output); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -17,6 +17,15 @@ func Str2float64(s string) float64 {
 	return value
 }
 
+// Remaining subtracts used from total. It returns -1 if one of the values is unknown (-1)
+// or used exceeds total, which happens with inconsistent device output.
+func Remaining(total float64, used float64) float64 {
+	if total == -1 || used == -1 || used > total {
+		return -1
+	}
+	return total - used
+}
+
 // Uptime2seconds converts uptime pieces to seconds in float64 
 func Uptime2seconds(w string, d string, h string, m string, s string) float64 {
 	f64w := Str2float64(w)*604800
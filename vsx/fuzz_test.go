@@ -0,0 +1,43 @@
+package vsx
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseStatus(f *testing.F) {
+	golden.Seeds(f, "vsx")
+	c := NewCollector().(*vsxCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("status") {
+			status, _ := p.Parse(out)
+			golden.CheckValues(t, status)
+		}
+	})
+}
+
+func FuzzParseLAGs(f *testing.F) {
+	golden.Seeds(f, "vsx")
+	c := NewCollector().(*vsxCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("lags") {
+			lags, _ := p.Parse(out)
+			golden.CheckValues(t, lags)
+		}
+	})
+}
+
+func FuzzParseISLStatistics(f *testing.F) {
+	golden.Seeds(f, "vsx")
+	c := NewCollector().(*vsxCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("isl") {
+			counters, _ := p.Parse(out)
+			golden.CheckValues(t, counters)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package vsx
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/slashdoom/aruba_exporter/util"
+)
+
+var (
+	fieldRegexp   = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z ]*?)\s*:\s*(\S.*?)\s*$`)
+	columnsRegexp = regexp.MustCompile(`\s{2,}`)
+)
+
+// ParseArubaCXStatus parses "show vsx status" and "show vsx brief". Both have "<name> : <state>" fields,
+// the roles of both peers are taken from the local and peer columns of the attribute table of the status.
+func (c *vsxCollector) ParseArubaCXStatus(output string) (Status, error) {
+	status := Status{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if m := fieldRegexp.FindStringSubmatch(line); m != nil {
+			switch strings.ToLower(m[1]) {
+			case "isl state", "isl channel":
+				status.ISLState = m[2]
+			case "isl mgmt channel":
+				status.ISLMgmtState = m[2]
+			case "config sync status":
+				status.ConfigSync = m[2]
+			case "device state":
+				status.DeviceState = m[2]
+			case "keepalive state":
+				status.KeepaliveState = m[2]
+			case "device role":
+				status.Role = m[2]
+			}
+			continue
+		}
+
+		columns := columnsRegexp.Split(strings.TrimSpace(line), -1)
+		if len(columns) == 3 && columns[0] == "Device Role" {
+			status.Role = columns[1]
+			status.PeerRole = columns[2]
+		}
+	}
+
+	status.Configured = status.ISLState != ""
+	return status, nil
+}
+
+// ParseArubaCXLAGs parses "show vsx config-consistency lacp", a table of the local and peer configurations
+// per LAG starting with its name. A LAG is consistent if all configurations are the same on both peers.
+func (c *vsxCollector) ParseArubaCXLAGs(output string) (map[string]*LAG, error) {
+	lags := make(map[string]*LAG)
+
+	var lag *LAG
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "Configurations") {
+			continue
+		}
+
+		columns := columnsRegexp.Split(line, -1)
+		if columns[0] == "Name" && len(columns) > 1 {
+			lag = &LAG{Name: columns[1], Consistent: true, Inconsistencies: []string{}}
+			lags[lag.Name] = lag
+			if len(columns) != 3 || columns[1] != columns[2] {
+				lag.Consistent = false
+				lag.Inconsistencies = append(lag.Inconsistencies, columns[0])
+			}
+			continue
+		}
+		if lag == nil {
+			continue
+		}
+
+		// a configuration without values is not set on both peers
+		if len(columns) == 1 {
+			continue
+		}
+		if len(columns) != 3 || columns[1] != columns[2] {
+			lag.Consistent = false
+			lag.Inconsistencies = append(lag.Inconsistencies, columns[0])
+		}
+	}
+
+	return lags, nil
+}
+
+// ParseArubaCXISLStatistics parses "show vsx isl statistics", a table of the packets sent and received over the
+// inter-switch link per packet type. The Tx and Rx columns are found by the table header.
+func (c *vsxCollector) ParseArubaCXISLStatistics(output string) (map[string]*ISLCounter, error) {
+	counters := make(map[string]*ISLCounter)
+
+	tx, rx, width := -1, -1, 0
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "---") {
+			continue
+		}
+
+		columns := columnsRegexp.Split(line, -1)
+		if i, j := index(columns, "Tx"), index(columns, "Rx"); i > 0 && j > 0 {
+			tx, rx, width = i, j, len(columns)
+			continue
+		}
+		if width == 0 || len(columns) != width {
+			continue
+		}
+
+		counter := &ISLCounter{
+			Type: strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(columns[0])),
+			Tx:   util.Str2float64(columns[tx]),
+			Rx:   util.Str2float64(columns[rx]),
+		}
+		if counter.Tx < 0 && counter.Rx < 0 {
+			continue
+		}
+		counters[counter.Type] = counter
+	}
+
+	return counters, nil
+}
+
+// index gets the position of a column, or -1 if there is no such column
+func index(columns []string, name string) int {
+	for i, c := range columns {
+		if c == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,89 @@
+package vsx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func TestParsers(t *testing.T) {
+	c := NewCollector().(*vsxCollector)
+	golden.TestParsers(t, c.parsers, "vsx")
+}
+
+func TestParseMalformedOutput(t *testing.T) {
+	c := &vsxCollector{}
+	status := func(out string) (interface{}, error) { return c.ParseArubaCXStatus(out) }
+	lags := func(out string) (interface{}, error) { return c.ParseArubaCXLAGs(out) }
+	isl := func(out string) (interface{}, error) { return c.ParseArubaCXISLStatistics(out) }
+	islHeader := "Packet Type                 Tx                  Rx\n--------------------------  ------------------  ------------------\n"
+
+	tests := []struct {
+		name   string
+		parse  func(string) (interface{}, error)
+		output string
+		want   interface{}
+	}{
+		{name: "status empty output", parse: status, want: Status{}},
+		{name: "status of a switch without VSX", parse: status, output: "VSX is not configured\n", want: Status{}},
+		{
+			name:   "status without values",
+			parse:  status,
+			output: "  ISL channel             :\n  Config Sync Status      :\n",
+			want:   Status{},
+		},
+		{
+			name:   "roles without peer column",
+			parse:  status,
+			output: "  ISL channel             : In-Sync\nDevice Role         primary\n",
+			want:   Status{Configured: true, ISLState: "In-Sync"},
+		},
+		{name: "LAGs empty output", parse: lags, want: map[string]*LAG{}},
+		{
+			name:   "LAG without peer column",
+			parse:  lags,
+			output: "Configurations          Local               Peer\nName                    lag1\n",
+			want:   map[string]*LAG{"lag1": {Name: "lag1", Inconsistencies: []string{"Name"}}},
+		},
+		{
+			name:   "configuration without LAG",
+			parse:  lags,
+			output: "Loop protect            disabled            enabled\n",
+			want:   map[string]*LAG{},
+		},
+		{name: "ISL statistics empty output", parse: isl, want: map[string]*ISLCounter{}},
+		{name: "ISL statistics empty table", parse: isl, output: "ISL Statistics\n" + islHeader, want: map[string]*ISLCounter{}},
+		{
+			name:   "ISL statistics without header",
+			parse:  isl,
+			output: "ISL Hello                   184302              184297\n",
+			want:   map[string]*ISLCounter{},
+		},
+		{
+			name:   "ISL statistics with a missing column",
+			parse:  isl,
+			output: islHeader + "ISL Hello                   184302\n",
+			want:   map[string]*ISLCounter{},
+		},
+		{
+			name:   "ISL statistics with non-numeric counters",
+			parse:  isl,
+			output: islHeader + "ISL Hello                   n/a                 184297\nMAC Sync                    -                   -\n",
+			want:   map[string]*ISLCounter{"isl_hello": {Type: "isl_hello", Tx: -1, Rx: 184297}},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := test.parse(test.output)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		golden.CheckValues(t, got)
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
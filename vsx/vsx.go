@@ -0,0 +1,28 @@
+package vsx
+
+// Status is the operational state of a VSX pair. Configured is false if VSX is not configured on the switch.
+type Status struct {
+	Configured     bool
+	ISLState       string
+	ISLMgmtState   string
+	ConfigSync     string
+	DeviceState    string
+	KeepaliveState string
+	Role           string
+	PeerRole       string
+}
+
+// LAG is a multi-chassis LAG and the configurations which differ between the peers
+type LAG struct {
+	Name            string
+	Consistent      bool
+	Inconsistencies []string
+}
+
+// ISLCounter is a packet counter of the inter-switch link by packet type (e.g. isl_hello).
+// Tx and Rx are -1 if the output contains no number for them.
+type ISLCounter struct {
+	Type string
+	Tx   float64
+	Rx   float64
+}
@@ -0,0 +1,152 @@
+package vsx
+
+import (
+	"github.com/slashdoom/aruba_exporter/collector"
+	"github.com/slashdoom/aruba_exporter/rpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "aruba_vsx_"
+
+var (
+	ConfiguredDesc    *prometheus.Desc
+	ISLInSyncDesc     *prometheus.Desc
+	ISLMgmtUpDesc     *prometheus.Desc
+	KeepaliveUpDesc   *prometheus.Desc
+	PeerUpDesc        *prometheus.Desc
+	ConfigSyncDesc    *prometheus.Desc
+	RoleDesc          *prometheus.Desc
+	LAGConsistentDesc *prometheus.Desc
+	ISLPacketsDesc    *prometheus.Desc
+)
+
+func init() {
+	collector.Register("vsx", false, NewCollector)
+
+	l := []string{"target"}
+
+	ConfiguredDesc = collector.NewDesc(prefix+"configured", "VSX is configured on the switch", l)
+	ISLInSyncDesc = collector.NewDesc(prefix+"isl_in_sync", "Inter-switch link is in sync", l)
+	ISLMgmtUpDesc = collector.NewDesc(prefix+"isl_mgmt_up", "Management channel over the inter-switch link is operational", l)
+	KeepaliveUpDesc = collector.NewDesc(prefix+"keepalive_up", "Keepalive to the peer is established", l)
+	PeerUpDesc = collector.NewDesc(prefix+"peer_up", "Peer is established", l)
+	ConfigSyncDesc = collector.NewDesc(prefix+"config_sync_in_sync", "Configuration is in sync with the peer", l)
+	RoleDesc = collector.NewDesc(prefix+"role", "Role of the switch and its peer in the VSX pair", []string{"target", "role", "peer_role"})
+	LAGConsistentDesc = collector.NewDesc(prefix+"lag_consistent", "Configuration of the multi-chassis LAG is the same on both peers", []string{"target", "lag"})
+	ISLPacketsDesc = collector.NewDesc(prefix+"isl_packets_total", "Packets sent (tx) and received (rx) over the inter-switch link by packet type", []string{"target", "type", "direction"})
+}
+
+type vsxCollector struct {
+	parsers *collector.Parsers
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	c := &vsxCollector{
+		parsers: collector.NewParsers("vsx"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *vsxCollector) registerParsers() {
+	c.parsers.Register("status", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show vsx status", "show vsx brief"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXStatus(out) },
+	})
+	c.parsers.Register("lags", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show vsx config-consistency lacp"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXLAGs(out) },
+	})
+	c.parsers.Register("isl", &collector.Parser{
+		OSType:   rpc.ArubaCXSwitch,
+		Commands: []string{"show vsx isl statistics"},
+		Parse:    func(out string) (interface{}, error) { return c.ParseArubaCXISLStatistics(out) },
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *vsxCollector) Parsers() *collector.Parsers {
+	return c.parsers
+}
+
+func (*vsxCollector) Name() string {
+	return "VSX"
+}
+
+func (c *vsxCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ConfiguredDesc
+	ch <- ISLInSyncDesc
+	ch <- ISLMgmtUpDesc
+	ch <- KeepaliveUpDesc
+	ch <- PeerUpDesc
+	ch <- ConfigSyncDesc
+	ch <- RoleDesc
+	ch <- LAGConsistentDesc
+	ch <- ISLPacketsDesc
+}
+
+func (c *vsxCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if !c.parsers.Supports("status", client.OSType) {
+		return nil
+	}
+
+	parsed, err := collector.Run(c.parsers, "status", client, ch, labelValues)
+	if err != nil || parsed == nil {
+		return err
+	}
+	status := parsed.(Status)
+
+	ch <- prometheus.MustNewConstMetric(ConfiguredDesc, prometheus.GaugeValue, bool2float64(status.Configured), labelValues...)
+	if !status.Configured {
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(ISLInSyncDesc, prometheus.GaugeValue, bool2float64(status.ISLState == "In-Sync"), labelValues...)
+	if status.ISLMgmtState != "" {
+		ch <- prometheus.MustNewConstMetric(ISLMgmtUpDesc, prometheus.GaugeValue, bool2float64(status.ISLMgmtState == "operational"), labelValues...)
+	}
+	ch <- prometheus.MustNewConstMetric(KeepaliveUpDesc, prometheus.GaugeValue, bool2float64(status.KeepaliveState == "Keepalive-Established"), labelValues...)
+	ch <- prometheus.MustNewConstMetric(PeerUpDesc, prometheus.GaugeValue, bool2float64(status.DeviceState == "Peer-Established"), labelValues...)
+	ch <- prometheus.MustNewConstMetric(ConfigSyncDesc, prometheus.GaugeValue, bool2float64(status.ConfigSync == "In-Sync"), labelValues...)
+	ch <- prometheus.MustNewConstMetric(RoleDesc, prometheus.GaugeValue, 1, append(labelValues, status.Role, status.PeerRole)...)
+
+	parsed, err = collector.Run(c.parsers, "lags", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	lags, _ := parsed.(map[string]*LAG)
+
+	for name, lag := range lags {
+		ch <- prometheus.MustNewConstMetric(LAGConsistentDesc, prometheus.GaugeValue, bool2float64(lag.Consistent), append(labelValues, name)...)
+	}
+
+	parsed, err = collector.Run(c.parsers, "isl", client, ch, labelValues)
+	if err != nil {
+		return err
+	}
+	counters, _ := parsed.(map[string]*ISLCounter)
+
+	for _, counter := range counters {
+		if counter.Tx >= 0 {
+			ch <- prometheus.MustNewConstMetric(ISLPacketsDesc, prometheus.CounterValue, counter.Tx, append(labelValues, counter.Type, "tx")...)
+		}
+		if counter.Rx >= 0 {
+			ch <- prometheus.MustNewConstMetric(ISLPacketsDesc, prometheus.CounterValue, counter.Rx, append(labelValues, counter.Type, "rx")...)
+		}
+	}
+
+	return nil
+}
+
+func bool2float64(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,44 @@
+package wireless
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+)
+
+func FuzzParseAccessPoints(f *testing.F) {
+	golden.Seeds(f, "wireless")
+	c := NewCollector().(*wirelessCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("accesspoints") {
+			aps, _ := p.Parse(out)
+			golden.CheckValues(t, aps)
+		}
+	})
+}
+
+func FuzzParseChannels(f *testing.F) {
+	golden.Seeds(f, "wireless")
+	c := NewCollector().(*wirelessCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("channels") {
+			parsed, _ := p.Parse(out)
+			golden.CheckValues(t, parsed.(WirelessChannels).Channels, "NoiseFloor")
+			golden.CheckValues(t, parsed.(WirelessChannels).Radios, "NoiseFloor", "Power")
+		}
+	})
+}
+
+func FuzzParseRadios(f *testing.F) {
+	golden.Seeds(f, "wireless")
+	c := NewCollector().(*wirelessCollector)
+
+	f.Fuzz(func(t *testing.T, out string) {
+		for _, p := range c.parsers.Registered("radios") {
+			radios, _ := p.Parse(out)
+			golden.CheckValues(t, radios, "NoiseFloor", "Power")
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package wireless
+
+import (
+	"testing"
+
+	"github.com/slashdoom/aruba_exporter/golden"
+	"github.com/slashdoom/aruba_exporter/rpc"
+)
+
+func TestParseChannels(t *testing.T) {
+	c := &wirelessCollector{}
+
+	out, found := golden.Sample(t, "wireless", rpc.ArubaInstant, []string{"show ap-env", "show ap arm rf-summary"})
+	if !found {
+		t.Skip("no sample")
+	}
+	channels, radios, err := c.ParseChannels(rpc.ArubaInstant, out)
+	golden.Assert(t, "channels/"+rpc.ArubaInstant, map[string]interface{}{"channels": channels, "radios": radios}, err)
+}
+
+func TestParseRadios(t *testing.T) {
+	c := &wirelessCollector{}
+
+	out, found := golden.Sample(t, "wireless", rpc.ArubaInstant, []string{"show ap monitor status"})
+	if !found {
+		t.Skip("no sample")
+	}
+	radios, err := c.ParseRadios(rpc.ArubaInstant, make(map[string]WirelessRadio), out)
+	golden.Assert(t, "radios/"+rpc.ArubaInstant, radios, err)
+}
@@ -47,6 +47,12 @@ type WirelessChannel struct {
 	IntfIndex   float64
 }
 
+// WirelessChannels are the channels and radios found in the channel stats of the APs
+type WirelessChannels struct {
+	Channels map[string]WirelessChannel
+	Radios   map[string]WirelessRadio
+}
+
 type WirelessBssid struct {
 	AccessPoint string
 	
@@ -1,7 +1,6 @@
 package wireless
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/slashdoom/aruba_exporter/collector"
@@ -26,24 +25,81 @@ var (
 )
 
 func init() {
+	collector.Register("wireless", true, NewCollector)
+
 	l := []string{"target", "name"}
-	apUp = prometheus.NewDesc(prefix+"ap_up", "Scrape of AP was successful", l, nil)
-	apController = prometheus.NewDesc(prefix+"ap_controller", "AP is Virtual Controller", l, nil)
-	apClients = prometheus.NewDesc(prefix+"ap_clients", "AP Connected Clients ", l, nil)
+	apUp = collector.NewDesc(prefix+"ap_up", "Scrape of AP was successful", l)
+	apController = collector.NewDesc(prefix+"ap_controller", "AP is Virtual Controller", l)
+	apClients = collector.NewDesc(prefix+"ap_clients", "AP Connected Clients ", l)
 	l = []string{"target", "ap", "channel", "band"}
-	channelNoiseDesc = prometheus.NewDesc(prefix+"channel_noise", "Channel Noise", l, nil)
-	channelUtilDesc = prometheus.NewDesc(prefix+"channel_utilization", "Channel Utilization", l, nil)
-	channelQualDesc = prometheus.NewDesc(prefix+"channel_quailty", "Channel Quality", l, nil)
-	channelCovrDesc = prometheus.NewDesc(prefix+"channel_coverage_index", "Channel Coverage Index", l, nil)
-	channelIntfDesc = prometheus.NewDesc(prefix+"channel_interference_index", "Channel Interference Index", l, nil)
+	channelNoiseDesc = collector.NewDesc(prefix+"channel_noise", "Channel Noise", l)
+	channelUtilDesc = collector.NewDesc(prefix+"channel_utilization", "Channel Utilization", l)
+	channelQualDesc = collector.NewDesc(prefix+"channel_quailty", "Channel Quality", l)
+	channelCovrDesc = collector.NewDesc(prefix+"channel_coverage_index", "Channel Coverage Index", l)
+	channelIntfDesc = collector.NewDesc(prefix+"channel_interference_index", "Channel Interference Index", l)
 }
 
 type wirelessCollector struct {
+	parsers *collector.Parsers
 }
 
 // NewCollector creates a new collector
 func NewCollector() collector.RPCCollector {
-	return &wirelessCollector{}
+	c := &wirelessCollector{
+		parsers: collector.NewParsers("wireless"),
+	}
+	c.registerParsers()
+
+	return c
+}
+
+func (c *wirelessCollector) registerParsers() {
+	for _, osType := range []string{rpc.ArubaController, rpc.ArubaInstant} {
+		osType := osType
+		c.parsers.Register("accesspoints", &collector.Parser{
+			OSType:   osType,
+			Commands: []string{"show summary"},
+			Parse:    func(out string) (interface{}, error) { return c.ParseAccessPoints(osType, out) },
+		})
+	}
+
+	// the radios are only taken from the ARM assignments of Instant APs
+	c.parsers.Register("channels", &collector.Parser{
+		OSType:   rpc.ArubaController,
+		Commands: []string{"show interface"},
+		Parse: func(out string) (interface{}, error) {
+			channels, _, err := c.ParseChannels(rpc.ArubaController, out)
+			return WirelessChannels{Channels: channels}, err
+		},
+	})
+	c.parsers.Register("channels", &collector.Parser{
+		OSType:   rpc.ArubaInstant,
+		Commands: []string{"show ap-env", "show ap arm rf-summary"},
+		Parse: func(out string) (interface{}, error) {
+			channels, radios, err := c.ParseChannels(rpc.ArubaInstant, out)
+			return WirelessChannels{Channels: channels, Radios: radios}, err
+		},
+	})
+
+	c.parsers.Register("radios", &collector.Parser{
+		OSType:   rpc.ArubaController,
+		Commands: []string{"show interface"},
+		Parse: func(out string) (interface{}, error) {
+			return c.ParseRadios(rpc.ArubaController, make(map[string]WirelessRadio), out)
+		},
+	})
+	c.parsers.Register("radios", &collector.Parser{
+		OSType:   rpc.ArubaInstant,
+		Commands: []string{"show ap monitor status"},
+		Parse: func(out string) (interface{}, error) {
+			return c.ParseRadios(rpc.ArubaInstant, make(map[string]WirelessRadio), out)
+		},
+	})
+}
+
+// Parsers gets the parsers of the collector
+func (c *wirelessCollector) Parsers() *collector.Parsers {
+	return c.parsers
 }
 
 // Name returns the name of the collector
@@ -65,31 +121,11 @@ func (*wirelessCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (c *wirelessCollector) CollectAccessPoints(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) (map[string]WirelessAccessPoint, error) {
-	var (
-		out string
-		aps map[string]WirelessAccessPoint
-		err error
-	)
-
-	switch client.OSType {
-	case "ArubaController":
-		out, err = client.RunCommand([]string{"show summary"})
-		if err != nil {
-			return make(map[string]WirelessAccessPoint), err
-		}
-		aps, err = c.ParseAccessPoints(client.OSType, out)
-	case "ArubaInstant":
-		out, err = client.RunCommand([]string{"show summary"})
-		if err != nil {
-			return make(map[string]WirelessAccessPoint), err
-		}
-		aps, err = c.ParseAccessPoints(client.OSType, out)
-	default:
-		err = errors.New("'CollectAccessPoints' is not implemented for " + client.OSType)
-	}
+	parsed, err := collector.Run(c.parsers, "accesspoints", client, ch, labelValues)
 	if err != nil {
 		return make(map[string]WirelessAccessPoint), err
 	}
+	aps := parsed.(map[string]WirelessAccessPoint)
 	for apName, apData := range aps {
 		l := append(labelValues, fmt.Sprintf("%v",apName))
 		
@@ -111,32 +147,12 @@ func (c *wirelessCollector) CollectAccessPoints(client *rpc.Client, ch chan<- pr
 
 // CollectChannels collects memory informations from Aruba Devices
 func (c *wirelessCollector) CollectChannels(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) (map[string]WirelessRadio, error) {
-	var (
-		out string
-		channels map[string]WirelessChannel
-		radios map[string]WirelessRadio
-		err error
-	)
-
-	switch client.OSType {
-	case "ArubaController":
-		out, err = client.RunCommand([]string{"show interface"})
-		if err != nil {
-			return make(map[string]WirelessRadio), err
-		}
-		channels, _, err = c.ParseChannels(client.OSType, out)
-	case "ArubaInstant":
-		out, err = client.RunCommand([]string{"show ap-env", "show ap arm rf-summary"})
-		if err != nil {
-			return make(map[string]WirelessRadio), err
-		}
-		channels, radios, err = c.ParseChannels(client.OSType, out)
-	default:
-		err = errors.New("'CollectChannels' is not implemented for " + client.OSType)
-	}
+	parsed, err := collector.Run(c.parsers, "channels", client, ch, labelValues)
 	if err != nil {
 		return make(map[string]WirelessRadio), err
 	}
+	channels := parsed.(WirelessChannels).Channels
+	radios := parsed.(WirelessChannels).Radios
 	for chChannel, chData := range channels {
 		log.Debugf("channel data: %+v", chData)
 		l := append(labelValues, fmt.Sprintf("%v", chData.AccessPoint), fmt.Sprintf("%v",chChannel), fmt.Sprintf("%v", chData.Band))
@@ -153,30 +169,12 @@ func (c *wirelessCollector) CollectChannels(client *rpc.Client, ch chan<- promet
 func (c *wirelessCollector) CollectRadios(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string, radios map[string]WirelessRadio) (error) {
 	log.Debugf("client: %+v", client)
 	log.Debugf("labelValues: %+v", labelValues)
-	var (
-		out string
-		err error
-	)
-
-	switch client.OSType {
-	case "ArubaController":
-		out, err = client.RunCommand([]string{"show interface"})
-		if err != nil {
-			return err
-		}
-		radios, err = c.ParseRadios(client.OSType, radios, out)
-	case "ArubaInstant":
-		out, err = client.RunCommand([]string{"show ap monitor status"})
-		if err != nil {
-			return err
-		}
-		radios, err = c.ParseRadios(client.OSType, radios, out)
-	default:
-		err = errors.New("'CollectRadios' is not implemented for " + client.OSType)
-	}
+
+	parsed, err := collector.Run(c.parsers, "radios", client, ch, labelValues)
 	if err != nil {
 		return err
 	}
+	radios = parsed.(map[string]WirelessRadio)
 	for radioId, radioData := range radios {
 		log.Debugf("radio data: %+v", radioData)
 		l := append(labelValues, fmt.Sprintf("%v", radioData.AccessPoint), fmt.Sprintf("%v",radioId), fmt.Sprintf("%v", radioData.Bssid))
@@ -195,7 +193,12 @@ func (c *wirelessCollector) Collect(client *rpc.Client, ch chan<- prometheus.Met
 	log.Debugf("client: %+v", client)
 	log.Debugf("labelValues: %+v", labelValues)
 	var err error
-	
+
+	// the wireless metrics are only available on controllers and APs
+	if !c.parsers.Supports("accesspoints", client.OSType) {
+		return nil
+	}
+
 	var aps map[string]WirelessAccessPoint 
 	aps, err = c.CollectAccessPoints(client, ch, labelValues)
 	if err != nil {